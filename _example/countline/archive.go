@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/KEINOS/go-countline/cl/archive"
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+//  countArchive
+// ----------------------------------------------------------------------------
+
+// countArchive prints the number of lines of each file in the archive and the
+// total, like "wc -l". The skipped binary files are reported to the notice.
+func countArchive(output, notice io.Writer, pathFile string) error {
+	result, err := archive.CountFile(pathFile)
+	if err != nil {
+		return errors.Wrap(err, "failed to count archive")
+	}
+
+	width := len(strconv.FormatUint(result.Total, 10))
+
+	for _, entry := range result.Entries {
+		fmt.Fprintf(output, "%*d %s\n", width, entry.Lines, entry.Path)
+	}
+
+	fmt.Fprintf(output, "%*d total\n", width, result.Total)
+
+	for _, pathEntry := range result.Skipped {
+		fmt.Fprintf(notice, "countline: %s: binary file skipped\n", pathEntry)
+	}
+
+	return nil
+}
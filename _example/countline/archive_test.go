@@ -0,0 +1,99 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zenizh/go-capturer"
+)
+
+// ============================================================================
+//  Tests
+// ============================================================================
+
+//nolint:paralleltest // do not parallelize due to temporary changing global variables
+func Test_main_archive(t *testing.T) {
+	oldOsArgs := os.Args
+	oldOsExit := osExit
+
+	defer func() {
+		os.Args = oldOsArgs
+		osExit = oldOsExit
+	}()
+
+	// Mock os.Exit() to capture the exit code
+	capturedCode := 0
+	osExit = func(code int) {
+		capturedCode = code
+	}
+
+	pathFile := filepath.Join(t.TempDir(), "bundle.zip")
+	require.NoError(t, os.WriteFile(pathFile, zipData(t, map[string]string{
+		"app.log":  "foo\nbar\n",
+		"icon.png": "\x89PNG\r\n\x1a\n\x00",
+	}), 0o600))
+
+	os.Args = []string{t.Name(), "--archive", pathFile}
+
+	var stdout string
+
+	stderr := capturer.CaptureStderr(func() {
+		stdout = capturer.CaptureStdout(func() {
+			main()
+		})
+	})
+
+	require.Equal(t, "2 app.log\n2 total\n", stdout)
+	require.Equal(t, "countline: icon.png: binary file skipped\n", stderr)
+	require.Equal(t, 0, capturedCode, "exit code should be 0")
+}
+
+func Test_countArchive(t *testing.T) {
+	t.Parallel()
+
+	pathFile := filepath.Join(t.TempDir(), "bundle.zip")
+	require.NoError(t, os.WriteFile(pathFile, zipData(t, map[string]string{
+		"a.txt": "a\n",
+		"b.txt": "b\nb\nb\nb\nb\nb\nb\nb\nb\nb\n",
+	}), 0o600))
+
+	output, notice := new(bytes.Buffer), new(bytes.Buffer)
+
+	require.NoError(t, countArchive(output, notice, pathFile))
+	require.Equal(t, " 1 a.txt\n10 b.txt\n11 total\n", output.String(), "numbers should be right-aligned")
+	require.Empty(t, notice.String())
+
+	err := countArchive(output, notice, filepath.Join(t.TempDir(), "missing.zip"))
+	require.ErrorContains(t, err, "failed to count archive")
+}
+
+// ============================================================================
+//  Helpers
+// ============================================================================
+
+// zipData returns the zip archive of the files sorted by the name.
+func zipData(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	writer := zip.NewWriter(&buf)
+
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		entry, err := writer.Create(name)
+		require.NoError(t, err)
+
+		_, err = entry.Write([]byte(files[name]))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, writer.Close())
+
+	return buf.Bytes()
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/KEINOS/go-countline/cl"
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+//  follow
+// ----------------------------------------------------------------------------
+
+// follow prints the number of lines of the file and keeps printing the updated
+// number as lines are appended until the context is canceled.
+//
+// Like "tail -F", it starts over if the file is truncated or replaced by another
+// file, such as a log rotation by rename. Only the appended part of the file is
+// read on each update.
+func follow(ctx context.Context, pathFile string, output, notice io.Writer, interval time.Duration) error {
+	tracker := &follower{
+		path:   filepath.Clean(pathFile),
+		output: output,
+		notice: notice,
+	}
+
+	defer tracker.close()
+
+	// The file must exist at the start
+	if err := tracker.open(); err != nil {
+		return err
+	}
+
+	watch := newWatcher(tracker.path, interval)
+	defer watch.Close()
+
+	for {
+		if err := tracker.update(); err != nil {
+			return err
+		}
+
+		if err := watch.Wait(ctx); err != nil {
+			if ctx.Err() != nil {
+				return nil // stopped by the user
+			}
+
+			return err
+		}
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  Type: follower
+// ----------------------------------------------------------------------------
+
+// follower keeps the state of the followed file between the updates.
+type follower struct {
+	output  io.Writer // destination of the counts
+	notice  io.Writer // destination of the truncation and rotation notices
+	osFile  *os.File  // currently followed file
+	path    string
+	state   cl.State
+	printed bool
+}
+
+// open opens the file of the path and resets the state.
+func (f *follower) open() error {
+	osFile, err := os.Open(f.path)
+	if err != nil {
+		return errors.Wrap(err, "failed to open file")
+	}
+
+	f.close()
+
+	f.osFile = osFile
+	f.state = cl.State{}
+	f.printed = false
+
+	return nil
+}
+
+// close closes the followed file if any.
+func (f *follower) close() {
+	if f.osFile != nil {
+		_ = f.osFile.Close()
+		f.osFile = nil
+	}
+}
+
+// update counts the appended lines and prints the number of lines if changed.
+func (f *follower) update() error {
+	if err := f.reopenIfReplaced(); err != nil {
+		return err
+	}
+
+	info, err := f.osFile.Stat()
+	if err != nil {
+		return errors.Wrap(err, "failed to get file info")
+	}
+
+	state, err := cl.Resume(f.state, f.osFile, info.Size())
+
+	switch {
+	case errors.Is(err, cl.ErrTruncated):
+		fmt.Fprintf(f.notice, "countline: %s: file truncated\n", f.path)
+
+		state, err = cl.Resume(cl.State{}, f.osFile, info.Size())
+	case errors.Is(err, cl.ErrRotated):
+		fmt.Fprintf(f.notice, "countline: %s: file overwritten\n", f.path)
+
+		state, err = cl.Resume(cl.State{}, f.osFile, info.Size())
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "failed to count lines")
+	}
+
+	if !f.printed || state.Lines() != f.state.Lines() {
+		fmt.Fprintln(f.output, state.Lines())
+
+		f.printed = true
+	}
+
+	f.state = state
+
+	return nil
+}
+
+// reopenIfReplaced reopens the file if the path points to another file than the
+// followed one, such as a new log file after the rotation.
+func (f *follower) reopenIfReplaced() error {
+	pathInfo, err := os.Stat(f.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil // keep following the old file until the new one appears
+		}
+
+		return errors.Wrap(err, "failed to get file info")
+	}
+
+	fileInfo, err := f.osFile.Stat()
+	if err == nil && os.SameFile(pathInfo, fileInfo) {
+		return nil
+	}
+
+	fmt.Fprintf(f.notice, "countline: %s has been replaced; following new file\n", f.path)
+
+	return f.open()
+}
+
+// ----------------------------------------------------------------------------
+//  Type: watcher
+// ----------------------------------------------------------------------------
+
+// watcher waits for the changes of the followed file.
+type watcher interface {
+	// Wait blocks until the file may have changed, the interval passes or the
+	// context is canceled.
+	Wait(ctx context.Context) error
+	// Close releases the resources of the watcher.
+	Close() error
+}
+
+// pollWatcher is the watcher that simply waits for the interval. It is the
+// fallback of the platforms without the file system notifications.
+type pollWatcher struct {
+	interval time.Duration
+}
+
+// Wait waits for the interval or the context to be canceled.
+func (w pollWatcher) Wait(ctx context.Context) error {
+	timer := time.NewTimer(w.interval)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Close does nothing.
+func (w pollWatcher) Close() error {
+	return nil
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+//  Tests
+// ============================================================================
+
+func Test_follow(t *testing.T) {
+	t.Parallel()
+
+	pathFile := filepath.Join(t.TempDir(), "app.log")
+	require.NoError(t, os.WriteFile(pathFile, []byte("foo\nbar\n"), 0o600))
+
+	output, notice, stop := runFollow(t, pathFile)
+	defer stop()
+
+	waitOutput(t, output, "2\n")
+
+	// Appended lines
+	appendFile(t, pathFile, "buzz\n")
+	waitOutput(t, output, "2\n3\n")
+
+	// The last line without a line break is counted
+	appendFile(t, pathFile, "qux")
+	waitOutput(t, output, "2\n3\n4\n")
+
+	// The same number of lines should not be printed again
+	appendFile(t, pathFile, "\n")
+	appendFile(t, pathFile, "quux\n")
+	waitOutput(t, output, "2\n3\n4\n5\n")
+
+	// Truncated
+	require.NoError(t, os.Truncate(pathFile, 0))
+	waitOutput(t, output, "2\n3\n4\n5\n0\n")
+	require.Contains(t, notice.String(), "file truncated")
+
+	appendFile(t, pathFile, "new\n")
+	waitOutput(t, output, "2\n3\n4\n5\n0\n1\n")
+
+	stop()
+
+	require.Empty(t, strings.TrimSpace(strings.ReplaceAll(notice.String(), "countline: "+pathFile+": file truncated", "")),
+		"no other notice should be printed")
+}
+
+func Test_follow_rotation(t *testing.T) {
+	t.Parallel()
+
+	pathFile := filepath.Join(t.TempDir(), "app.log")
+	require.NoError(t, os.WriteFile(pathFile, []byte("foo\nbar\nbuzz\n"), 0o600))
+
+	output, notice, stop := runFollow(t, pathFile)
+	defer stop()
+
+	waitOutput(t, output, "3\n")
+
+	// Rotate the file by rename. The old file is kept until the new one appears.
+	require.NoError(t, os.Rename(pathFile, pathFile+".1"))
+	appendFile(t, pathFile+".1", "qux\n")
+	waitOutput(t, output, "3\n4\n")
+
+	replaceFile(t, pathFile, "new\n")
+	waitOutput(t, output, "3\n4\n1\n")
+	require.Contains(t, notice.String(), "has been replaced; following new file")
+
+	// Replaced again by rename
+	replaceFile(t, pathFile, "a\nb\n")
+	waitOutput(t, output, "3\n4\n1\n2\n")
+}
+
+func Test_follow_overwritten(t *testing.T) {
+	t.Parallel()
+
+	pathFile := filepath.Join(t.TempDir(), "app.log")
+	require.NoError(t, os.WriteFile(pathFile, []byte("foo\nbar\n"), 0o600))
+
+	tracker := &follower{path: pathFile, output: new(bytes.Buffer), notice: new(bytes.Buffer)}
+	defer tracker.close()
+
+	require.NoError(t, tracker.open())
+	require.NoError(t, tracker.update())
+
+	// Overwrite the same file with the longer contents
+	require.NoError(t, os.WriteFile(pathFile, []byte("1234567890\n"), 0o600))
+	require.NoError(t, tracker.update())
+
+	require.Equal(t, "2\n1\n", tracker.output.(*bytes.Buffer).String())
+	require.Contains(t, tracker.notice.(*bytes.Buffer).String(), "file overwritten")
+}
+
+func Test_follow_missing_file(t *testing.T) {
+	t.Parallel()
+
+	err := follow(context.Background(), filepath.Join(t.TempDir(), "missing.log"),
+		new(bytes.Buffer), new(bytes.Buffer), time.Millisecond)
+
+	require.ErrorContains(t, err, "failed to open file")
+}
+
+func Test_follow_stat_fail(t *testing.T) {
+	t.Parallel()
+
+	pathFile := filepath.Join(t.TempDir(), "app.log")
+	require.NoError(t, os.WriteFile(pathFile, []byte("foo\n"), 0o600))
+
+	tracker := &follower{path: pathFile, output: new(bytes.Buffer), notice: new(bytes.Buffer)}
+
+	require.NoError(t, tracker.open())
+
+	defer tracker.close()
+
+	// Path under a regular file fails other than "not exist"
+	tracker.path = filepath.Join(pathFile, "app.log")
+
+	err := tracker.update()
+
+	require.ErrorContains(t, err, "failed to get file info")
+}
+
+func Test_pollWatcher(t *testing.T) {
+	t.Parallel()
+
+	watch := pollWatcher{interval: time.Millisecond}
+	defer watch.Close()
+
+	require.NoError(t, watch.Wait(context.Background()), "it should return after the interval")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	require.ErrorIs(t, watch.Wait(ctx), context.Canceled)
+}
+
+func Test_newWatcher(t *testing.T) {
+	t.Parallel()
+
+	pathFile := filepath.Join(t.TempDir(), "app.log")
+
+	watch := newWatcher(pathFile, time.Hour)
+	defer watch.Close()
+
+	done := make(chan error)
+
+	go func() {
+		done <- watch.Wait(context.Background())
+	}()
+
+	// Wait returns on the file creation without waiting for the interval, or
+	// after the hour if the watcher is polling.
+	if _, ok := watch.(pollWatcher); !ok {
+		require.NoError(t, os.WriteFile(pathFile, []byte("foo\n"), 0o600))
+		require.NoError(t, <-done)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	require.ErrorIs(t, watch.Wait(ctx), context.Canceled)
+}
+
+// ============================================================================
+//  Helpers
+// ============================================================================
+
+// SyncBuffer is a bytes.Buffer safe for concurrent use.
+type SyncBuffer struct {
+	buf bytes.Buffer
+	mu  sync.Mutex
+}
+
+func (b *SyncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p) //nolint:wrapcheck // never fails
+}
+
+func (b *SyncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.String()
+}
+
+// runFollow runs follow in background. Call the returned function to stop it.
+func runFollow(t *testing.T, pathFile string) (*SyncBuffer, *SyncBuffer, func()) {
+	t.Helper()
+
+	output, notice := new(SyncBuffer), new(SyncBuffer)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+
+	go func() {
+		done <- follow(ctx, pathFile, output, notice, 10*time.Millisecond)
+	}()
+
+	var once sync.Once
+
+	return output, notice, func() {
+		once.Do(func() {
+			cancel()
+			require.NoError(t, <-done, "follow should stop without error")
+		})
+	}
+}
+
+// waitOutput waits until the output becomes the expected one.
+func waitOutput(t *testing.T, output *SyncBuffer, expect string) {
+	t.Helper()
+
+	require.Eventually(t, func() bool {
+		return output.String() == expect
+	}, 5*time.Second, time.Millisecond, "expect: %q, actual: %q", expect, output.String())
+}
+
+// replaceFile replaces the file with a new one of the data atomically.
+func replaceFile(t *testing.T, pathFile, data string) {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(pathFile+".tmp", []byte(data), 0o600))
+	require.NoError(t, os.Rename(pathFile+".tmp", pathFile))
+}
+
+// appendFile appends the data to the file.
+func appendFile(t *testing.T, pathFile, data string) {
+	t.Helper()
+
+	osFile, err := os.OpenFile(pathFile, os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+
+	defer osFile.Close()
+
+	_, err = osFile.WriteString(data)
+	require.NoError(t, err)
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Output formats of the counts.
+const (
+	formatText   = "text"   // "wc -l" like lines
+	formatJSON   = "json"   // a single document of all the files and the total
+	formatNDJSON = "ndjson" // a record per line
+	formatCSV    = "csv"    // comma-separated values with the header
+	formatTSV    = "tsv"    // tab-separated values with the header
+)
+
+// schemaVersion is the version of the JSON output described in result.schema.json.
+// It is incremented on any change of the fields.
+const schemaVersion = 1
+
+// isFormat reports whether the name is one of the supported output formats.
+func isFormat(name string) bool {
+	switch name {
+	case formatText, formatJSON, formatNDJSON, formatCSV, formatTSV:
+		return true
+	}
+
+	return false
+}
+
+// ----------------------------------------------------------------------------
+//  Type: Result
+// ----------------------------------------------------------------------------
+
+// Result is the count of a file. All the output formats are rendered from it.
+type Result struct {
+	Err      error // error occurred while counting, if any
+	Path     string
+	Lines    int
+	Bytes    int64 // size of the file, or the bytes read for STDIN and pipes
+	Duration time.Duration
+}
+
+// record is the JSON representation of Result. The field names are the stable
+// schema of the output. Do not change them without bumping schemaVersion.
+type record struct {
+	Path     string  `json:"path"`
+	Lines    int     `json:"lines"`
+	Bytes    int64   `json:"bytes"`
+	Duration float64 `json:"duration"` // in seconds
+	Error    *string `json:"error"`    // null on success
+}
+
+// summary is the JSON document of the "json" format.
+type summary struct {
+	Version int      `json:"version"`
+	Files   []record `json:"files"`
+	Total   total    `json:"total"`
+}
+
+// total is the sum of the succeeded files.
+type total struct {
+	Lines    int     `json:"lines"`
+	Bytes    int64   `json:"bytes"`
+	Duration float64 `json:"duration"` // in seconds
+}
+
+// record returns the JSON representation of the result.
+func (r Result) record() record {
+	rec := record{
+		Path:     r.Path,
+		Lines:    r.Lines,
+		Bytes:    r.Bytes,
+		Duration: r.Duration.Seconds(),
+	}
+
+	if r.Err != nil {
+		msg := r.Err.Error()
+		rec.Error = &msg
+	}
+
+	return rec
+}
+
+// fields returns the result as the values of the CSV and TSV columns in the order
+// of csvHeader. The error is empty on success.
+func (r Result) fields() []string {
+	msg := ""
+	if r.Err != nil {
+		msg = r.Err.Error()
+	}
+
+	return []string{
+		r.Path,
+		strconv.Itoa(r.Lines),
+		strconv.FormatInt(r.Bytes, 10),
+		strconv.FormatFloat(r.Duration.Seconds(), 'f', -1, 64),
+		msg,
+	}
+}
+
+// csvHeader is the header line of the CSV and TSV formats.
+var csvHeader = []string{"path", "lines", "bytes", "duration", "error"}
+
+// sumResults returns the total of the succeeded results.
+func sumResults(results []Result) total {
+	var sum total
+
+	for _, result := range results {
+		if result.Err != nil {
+			continue
+		}
+
+		sum.Lines += result.Lines
+		sum.Bytes += result.Bytes
+		sum.Duration += result.Duration.Seconds()
+	}
+
+	return sum
+}
+
+// ----------------------------------------------------------------------------
+//  writeResults
+// ----------------------------------------------------------------------------
+
+// writeResults writes the results to the output in the format.
+func writeResults(output io.Writer, format string, results []Result) error {
+	switch format {
+	case formatJSON:
+		return writeJSON(output, results)
+	case formatNDJSON:
+		return writeNDJSON(output, results)
+	case formatCSV:
+		return writeCSV(output, ',', results)
+	case formatTSV:
+		return writeCSV(output, '\t', results)
+	case formatText:
+		writeText(output, results)
+
+		return nil
+	}
+
+	return errors.Errorf("unknown format: %q", format)
+}
+
+// writeText writes the results as "count path" lines, and the "total" line if
+// more than one file is given, like "wc -l". The name is omitted for STDIN and
+// the failed files are skipped.
+func writeText(output io.Writer, results []Result) {
+	sum := sumResults(results)
+	width := len(strconv.Itoa(sum.Lines))
+
+	for _, result := range results {
+		switch {
+		case result.Err != nil:
+			continue
+		case result.Path == pathStdin:
+			fmt.Fprintf(output, "%*d\n", width, result.Lines)
+		default:
+			fmt.Fprintf(output, "%*d %s\n", width, result.Lines, result.Path)
+		}
+	}
+
+	if len(results) > 1 {
+		fmt.Fprintf(output, "%*d total\n", width, sum.Lines)
+	}
+}
+
+// writeJSON writes the results and the total as a single JSON document.
+func writeJSON(output io.Writer, results []Result) error {
+	doc := summary{
+		Version: schemaVersion,
+		Files:   make([]record, 0, len(results)),
+		Total:   sumResults(results),
+	}
+
+	for _, result := range results {
+		doc.Files = append(doc.Files, result.record())
+	}
+
+	encoder := json.NewEncoder(output)
+	encoder.SetIndent("", "  ")
+
+	return errors.Wrap(encoder.Encode(doc), "failed to write JSON")
+}
+
+// writeNDJSON writes the results as a JSON record per line. The total is not
+// written so that the records can be streamed and concatenated.
+func writeNDJSON(output io.Writer, results []Result) error {
+	encoder := json.NewEncoder(output)
+
+	for _, result := range results {
+		if err := encoder.Encode(result.record()); err != nil {
+			return errors.Wrap(err, "failed to write JSON")
+		}
+	}
+
+	return nil
+}
+
+// writeCSV writes the results as the values separated by the comma with the
+// header line.
+func writeCSV(output io.Writer, comma rune, results []Result) error {
+	writer := csv.NewWriter(output)
+	writer.Comma = comma
+
+	_ = writer.Write(csvHeader) // the error is reported by Error below
+
+	for _, result := range results {
+		_ = writer.Write(result.fields())
+	}
+
+	writer.Flush()
+
+	return errors.Wrap(writer.Error(), "failed to write values")
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// updateGolden rewrites the golden files with the current outputs.
+//
+//	go test -run Test_writeResults_golden -update
+var updateGolden = flag.Bool("update", false, "update the golden files")
+
+// ============================================================================
+//  Tests
+// ============================================================================
+
+func Test_writeResults_golden(t *testing.T) {
+	t.Parallel()
+
+	results := []Result{
+		{Path: "app.log", Lines: 1234, Bytes: 56789, Duration: 1500 * time.Microsecond},
+		{Path: "dir/with, comma\tand tab.txt", Lines: 2, Bytes: 8, Duration: time.Millisecond},
+		{Path: "missing.log", Err: errors.New(`failed to open file: "missing.log"`)},
+		{Path: pathStdin, Lines: 10, Bytes: 42, Duration: 2 * time.Second},
+	}
+
+	for _, format := range []string{formatText, formatJSON, formatNDJSON, formatCSV, formatTSV} {
+		t.Run(format, func(t *testing.T) {
+			t.Parallel()
+
+			var output bytes.Buffer
+
+			require.NoError(t, writeResults(&output, format, results))
+
+			pathGolden := filepath.Join("testdata", "golden", "output."+format)
+
+			if *updateGolden {
+				require.NoError(t, os.WriteFile(pathGolden, output.Bytes(), 0o600))
+			}
+
+			expect, err := os.ReadFile(pathGolden)
+
+			require.NoError(t, err)
+			require.Equal(t, string(expect), output.String())
+		})
+	}
+}
+
+func Test_writeResults_empty(t *testing.T) {
+	t.Parallel()
+
+	var output bytes.Buffer
+
+	require.NoError(t, writeResults(&output, formatJSON, nil))
+	require.JSONEq(t, `{"version":1,"files":[],"total":{"lines":0,"bytes":0,"duration":0}}`, output.String(),
+		"files should be an empty array instead of null")
+}
+
+func Test_writeResults_fail(t *testing.T) {
+	t.Parallel()
+
+	results := []Result{{Path: "app.log", Lines: 1}}
+
+	for _, format := range []string{formatJSON, formatNDJSON, formatCSV, formatTSV} {
+		err := writeResults(failWriter{}, format, results)
+
+		require.ErrorContains(t, err, "forced write error", "format: %s", format)
+	}
+
+	err := writeResults(new(bytes.Buffer), "xml", results)
+
+	require.ErrorContains(t, err, `unknown format: "xml"`)
+}
+
+func Test_isFormat(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"text", "json", "ndjson", "csv", "tsv"} {
+		require.True(t, isFormat(name), name)
+	}
+
+	for _, name := range []string{"", "JSON", "xml"} {
+		require.False(t, isFormat(name), name)
+	}
+}
+
+// The schema must describe the fields actually written.
+func Test_schema(t *testing.T) {
+	t.Parallel()
+
+	data, err := os.ReadFile("result.schema.json")
+	require.NoError(t, err)
+
+	type object struct {
+		Properties map[string]json.RawMessage `json:"properties"`
+		Required   []string                   `json:"required"`
+	}
+
+	var schema struct {
+		Defs struct {
+			Record object `json:"record"`
+		} `json:"$defs"`
+		object
+	}
+
+	require.NoError(t, json.Unmarshal(data, &schema))
+
+	var totalSchema object
+
+	require.NoError(t, json.Unmarshal(schema.Properties["total"], &totalSchema))
+
+	for _, test := range []struct {
+		value  any
+		schema object
+	}{
+		{value: summary{}, schema: schema.object},
+		{value: record{}, schema: schema.Defs.Record},
+		{value: total{}, schema: totalSchema},
+	} {
+		names := jsonNames(t, test.value)
+
+		require.ElementsMatch(t, names, keys(test.schema.Properties), "properties of %T", test.value)
+		require.ElementsMatch(t, names, test.schema.Required, "required of %T", test.value)
+	}
+
+	require.Contains(t, string(schema.Properties["version"]), `"const": 1`,
+		"schema version should be the same as schemaVersion")
+	require.Equal(t, 1, schemaVersion)
+	require.Equal(t, strings.Join(csvHeader, ","), strings.Join(jsonNames(t, record{}), ","),
+		"CSV columns should be in the same order as the record fields")
+}
+
+// ============================================================================
+//  Helpers
+// ============================================================================
+
+// jsonNames returns the JSON field names of the struct in the order written.
+func jsonNames(t *testing.T, value any) []string {
+	t.Helper()
+
+	data, err := json.Marshal(value)
+	require.NoError(t, err)
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	names := []string{}
+
+	// Read the keys of the top level object
+	_, err = decoder.Token()
+	require.NoError(t, err)
+
+	for decoder.More() {
+		key, err := decoder.Token()
+		require.NoError(t, err)
+
+		names = append(names, key.(string)) //nolint:forcetypeassert // keys are always strings
+
+		var skip json.RawMessage
+
+		require.NoError(t, decoder.Decode(&skip))
+	}
+
+	return names
+}
+
+// keys returns the keys of the map.
+func keys(m map[string]json.RawMessage) []string {
+	result := make([]string, 0, len(m))
+
+	for key := range m {
+		result = append(result, key)
+	}
+
+	return result
+}
@@ -2,38 +2,344 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/KEINOS/go-countline/cl"
+	"github.com/KEINOS/go-countline/cl/strategy"
+	"github.com/KEINOS/go-countline/cl/walk"
 	"github.com/pkg/errors"
 )
 
 var msgHelp = `cl - Count the number of lines in a file.
+	Files compressed in gzip, bzip2 or zlib are decompressed. LZW (.Z) is
+	not supported.
 Usage:
-	cl [file]
+	cl [options] [file ...]
+	cl sloc DIR
+	With no file, or when file is "-", read STDIN. With more than one file,
+	the total is printed as well.
+Commands:
+	sloc DIR    Count the blank, comment and code lines of the source files
+	            under DIR per language, like "cloc".
+Options:
+	--progress  Show the progress bar on STDERR while counting.
+	--follow    Keep printing the number of lines as the file grows, like
+	            "tail -F". It follows the file truncation and rotation as well.
+	-e PATTERN  Count only the lines that match the regular expression
+	            PATTERN, like "grep -c".
+	-v          Count the lines that do NOT match the PATTERN of -e.
+	-r          Count the lines of each file under the directory given as
+	            the [file] recursively. Files ignored by .gitignore are skipped.
+	--include PATTERN
+	            Count only the files that match the glob PATTERN with -r,
+	            such as "*.go" or "cmd/**/*.go". Can be given more than once.
+	--exclude PATTERN
+	            Skip the files and directories that match the glob PATTERN
+	            with -r. Can be given more than once.
+	--archive   Count the lines of each file in the tar, tar.gz or zip
+	            archive without extracting it. Binary files are skipped.
+	--strategy NAME
+	            Count with the implementation registered as NAME, such as
+	            "default", "byteloop", "fanout", "readline", "scanner" or
+	            "transform", to compare them.
+	--format FORMAT
+	            Print the counts in FORMAT: text (default), json, ndjson,
+	            csv or tsv. Each record has the path, lines, bytes, duration
+	            and error. See result.schema.json for the JSON schema.
 `
 
 // osExit is a copy of os.Exit() to be able to mock it in tests.
 var osExit = os.Exit
 
+// progressInterval is the interval to update the progress bar.
+var progressInterval = 200 * time.Millisecond
+
+// followInterval is the maximum interval to check the followed file.
+var followInterval = time.Second
+
 func main() {
-	const lenArgs = 2 // program name and the file path
+	if len(os.Args) > 1 && os.Args[1] == "sloc" {
+		ExitOnError(runSloc(os.Stdout, os.Stderr, os.Args[2:]))
+
+		return
+	}
+
+	conf, err := parseArgs(os.Args[1:])
+	ExitOnError(err)
+
+	switch {
+	case conf.recursive:
+		opts := walk.Options{Include: conf.include, Exclude: conf.exclude}
+
+		ExitOnError(countTree(os.Stdout, os.Stderr, conf.paths[0], opts))
+	case conf.inArchive:
+		ExitOnError(countArchive(os.Stdout, os.Stderr, conf.paths[0]))
+	case conf.followFile:
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		ExitOnError(follow(ctx, conf.paths[0], os.Stdout, os.Stderr, followInterval))
+	default:
+		if !countFiles(os.Stdout, os.Stderr, conf.paths, conf.format, conf.counter()) {
+			osExit(1)
+		}
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  Type: config
+// ----------------------------------------------------------------------------
+
+// config is the parsed command line arguments.
+type config struct {
+	strategy     strategy.Strategy // nil to use the built-in one
+	pattern      string
+	format       string   // output format of the counts
+	strategyName string   // name of the strategy given by --strategy
+	paths        []string // "-" is STDIN
+	include      patternList
+	exclude      patternList
+	matching     bool // -e is given
+	invert       bool
+	showProgress bool
+	followFile   bool
+	inArchive    bool
+	recursive    bool
+}
+
+// parseArgs parses the command line arguments. STDIN is read if no path is
+// given. The modes which read a single file or directory, such as "--follow",
+// require exactly one path.
+func parseArgs(args []string) (*config, error) {
+	conf := new(config)
+
+	flags := flag.NewFlagSet("cl", flag.ContinueOnError)
+	flags.SetOutput(io.Discard) // print the help message by ExitOnError instead
+
+	flags.BoolVar(&conf.showProgress, "progress", false, "show the progress bar")
+	flags.BoolVar(&conf.followFile, "follow", false, "follow the file as it grows")
+	flags.StringVar(&conf.pattern, "e", "", "count the lines that match the pattern")
+	flags.BoolVar(&conf.invert, "v", false, "count the lines that do not match the pattern")
+	flags.BoolVar(&conf.inArchive, "archive", false, "count the lines of each file in the archive")
+	flags.BoolVar(&conf.recursive, "r", false, "count the lines of the files under the directory")
+	flags.Var(&conf.include, "include", "glob pattern of the files to count with -r")
+	flags.Var(&conf.exclude, "exclude", "glob pattern of the files to skip with -r")
+	flags.StringVar(&conf.format, "format", formatText, "output format of the counts")
+	flags.StringVar(&conf.strategyName, "strategy", "", "name of the implementation to count the lines")
+
+	if err := flags.Parse(args); err != nil {
+		return nil, errors.Wrap(err, "failed to parse arguments")
+	}
+
+	flags.Visit(func(f *flag.Flag) { conf.matching = conf.matching || f.Name == "e" })
+
+	conf.paths = flags.Args()
+
+	if err := conf.validate(); err != nil {
+		return nil, err
+	}
+
+	if len(conf.paths) == 0 {
+		conf.paths = []string{pathStdin}
+	}
+
+	return conf, nil
+}
+
+// singlePath returns true if the mode reads a single file or directory.
+func (c *config) singlePath() bool {
+	return c.recursive || c.inArchive || c.followFile
+}
+
+// validate checks the combination of the arguments and looks up the strategy.
+func (c *config) validate() error {
+	if c.invert && !c.matching {
+		return errors.New("-v requires -e PATTERN")
+	}
+
+	if !isFormat(c.format) {
+		return errors.Errorf("unknown format: %q", c.format)
+	}
+
+	if c.singlePath() {
+		if len(c.paths) != 1 {
+			return errors.New("invalid number of arguments")
+		}
+
+		if c.format != formatText {
+			return errors.New("--format is not supported with -r, --archive and --follow")
+		}
+	}
+
+	if c.strategyName == "" {
+		return nil
+	}
+
+	if c.singlePath() || c.matching || c.showProgress {
+		return errors.New("--strategy is not supported with -r, --archive, --follow, -e and --progress")
+	}
+
+	found, ok := strategy.Lookup(c.strategyName)
+	if !ok {
+		return errors.Errorf("unknown strategy: %q (available: %s)",
+			c.strategyName, strings.Join(strategy.Names(), ", "))
+	}
+
+	c.strategy = found
+
+	return nil
+}
 
-	if len(os.Args) != lenArgs {
-		ExitOnError(errors.New("invalid number of arguments"))
+// counter returns the function to count the lines of a file in the mode.
+func (c *config) counter() countFunc {
+	switch {
+	case c.matching:
+		return func(input *inputFile) (int, error) {
+			return countMatching(input, c.pattern, c.invert)
+		}
+	case c.showProgress:
+		return countWithProgress
+	case c.strategy != nil:
+		return func(input *inputFile) (int, error) {
+			return countWithStrategy(input, c.strategy)
+		}
+	default:
+		return countFile
 	}
+}
+
+// ----------------------------------------------------------------------------
+//  countFiles
+// ----------------------------------------------------------------------------
+
+// pathStdin is the path argument to read STDIN.
+const pathStdin = "-"
+
+// osStdin is a copy of os.Stdin to be able to mock it in tests.
+var osStdin = os.Stdin
+
+// countFiles counts the lines of the files and prints the results in the format.
+// The files failed to count are reported to the notice as well. It returns false
+// if any of them failed.
+func countFiles(output, notice io.Writer, paths []string, format string, count countFunc) bool {
+	results := make([]Result, 0, len(paths))
+	succeeded := true
+
+	for _, pathFile := range paths {
+		result := countPath(pathFile, count)
+		if result.Err != nil {
+			fmt.Fprintf(notice, "countline: %s: %v\n", pathFile, result.Err)
+
+			succeeded = false
+		}
+
+		results = append(results, result)
+	}
+
+	if err := writeResults(output, format, results); err != nil {
+		fmt.Fprintf(notice, "countline: %v\n", err)
+
+		return false
+	}
+
+	return succeeded
+}
+
+// countFunc is the function to count the lines of the opened file.
+type countFunc func(input *inputFile) (int, error)
+
+// countPath opens the file of the path and counts the lines with the function.
+func countPath(pathFile string, count countFunc) Result {
+	start := time.Now()
+	result := Result{Path: pathFile}
+
+	input, err := openFile(pathFile)
+	if err != nil {
+		result.Err = err
+
+		return result
+	}
+
+	defer func() { _ = input.Close() }()
+
+	result.Lines, result.Err = count(input)
+	result.Bytes = input.size()
+	result.Duration = time.Since(start)
+
+	return result
+}
+
+// ----------------------------------------------------------------------------
+//  Type: inputFile
+// ----------------------------------------------------------------------------
 
-	pathFile := os.Args[1]
+// inputFile is the opened file or STDIN to count. It counts the bytes read since
+// the size of STDIN is unknown beforehand.
+type inputFile struct {
+	file      *os.File
+	path      string
+	bytesRead int64
+}
+
+// openFile opens the file of the path, or returns STDIN for "-".
+func openFile(pathFile string) (*inputFile, error) {
+	if pathFile == pathStdin {
+		return &inputFile{file: osStdin, path: pathFile}, nil
+	}
 
 	osFile, err := os.Open(filepath.Clean(pathFile))
-	ExitOnError(err)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open file")
+	}
 
-	count, err := cl.CountLines(osFile)
-	ExitOnError(err)
+	return &inputFile{file: osFile, path: pathFile}, nil
+}
+
+// Read reads from the file and counts the bytes read.
+func (f *inputFile) Read(p []byte) (int, error) {
+	n, err := f.file.Read(p)
+	f.bytesRead += int64(n)
 
-	fmt.Println(count)
+	return n, err //nolint:wrapcheck // io.EOF must not be wrapped
+}
+
+// Close closes the file. STDIN is left open.
+func (f *inputFile) Close() error {
+	if f.path == pathStdin {
+		return nil
+	}
+
+	return errors.Wrap(f.file.Close(), "failed to close file")
+}
+
+// regularSize returns the size of the regular file. It returns -1 for the others,
+// such as pipes.
+func (f *inputFile) regularSize() int64 {
+	info, err := f.file.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		return -1
+	}
+
+	return info.Size()
+}
+
+// size returns the size of the input. It is the bytes read if the size is unknown
+// beforehand, such as pipes.
+func (f *inputFile) size() int64 {
+	if size := f.regularSize(); size >= 0 {
+		return size
+	}
+
+	return f.bytesRead
 }
 
 func ExitOnError(err error) {
@@ -44,3 +350,129 @@ func ExitOnError(err error) {
 		osExit(1)
 	}
 }
+
+// ----------------------------------------------------------------------------
+//  countFile
+// ----------------------------------------------------------------------------
+
+// countFile counts the lines of the file. The compressed file is decompressed
+// while counting.
+func countFile(input *inputFile) (int, error) {
+	decompressed, format, err := cl.Decompress(input)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to read file")
+	}
+
+	// Count by the path to use the memory-mapped fast path. Only regular files
+	// are reopened since pipes and FIFOs can not be read twice.
+	if format == cl.Uncompressed && input.path != pathStdin && input.regularSize() >= 0 {
+		return cl.CountLinesFile(input.path)
+	}
+
+	return cl.CountLines(decompressed)
+}
+
+// countWithStrategy counts the lines of the file with the strategy instead of the
+// built-in implementation. The compressed file is decompressed while counting.
+func countWithStrategy(input *inputFile, use strategy.Strategy) (int, error) {
+	decompressed, _, err := cl.Decompress(input)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to read file")
+	}
+
+	return cl.CountLinesWithOptions(decompressed, cl.WithStrategy(use))
+}
+
+// ----------------------------------------------------------------------------
+//  Progress bar
+// ----------------------------------------------------------------------------
+
+// countWithProgress counts the lines of the file while drawing the progress bar
+// on STDERR.
+func countWithProgress(input *inputFile) (int, error) {
+	decompressed, format, err := cl.Decompress(input)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to read file")
+	}
+
+	size := int64(-1) // unknown size, such as pipes and the decompressed size
+
+	if format == cl.Uncompressed {
+		size = input.regularSize()
+	}
+
+	bar := newProgressBar(os.Stderr, size)
+
+	count, err := cl.CountLinesWithOptions(decompressed, cl.Options{
+		Progress:         bar.Update,
+		ProgressInterval: progressInterval,
+	})
+
+	fmt.Fprintln(os.Stderr) // end the progress line
+
+	return count, err
+}
+
+// progressBar draws a single line progress bar with the throughput and ETA.
+type progressBar struct {
+	start  time.Time
+	output io.Writer
+	size   int64 // total size of the input. Negative if unknown.
+}
+
+// widthBar is the number of characters of the bar.
+const widthBar = 30
+
+func newProgressBar(output io.Writer, size int64) *progressBar {
+	return &progressBar{
+		start:  time.Now(),
+		output: output,
+		size:   size,
+	}
+}
+
+// Update redraws the progress bar. It is the callback of cl.Options.Progress.
+func (p *progressBar) Update(bytesRead, linesSoFar int64) {
+	fmt.Fprint(p.output, "\r"+p.render(bytesRead, linesSoFar, time.Since(p.start)))
+}
+
+// render returns the progress line of the given state.
+func (p *progressBar) render(bytesRead, linesSoFar int64, elapsed time.Duration) string {
+	throughput := float64(0)
+	if elapsed > 0 {
+		throughput = float64(bytesRead) / elapsed.Seconds()
+	}
+
+	stats := fmt.Sprintf("%s %s/s lines: %d", formatBytes(float64(bytesRead)), formatBytes(throughput), linesSoFar)
+
+	if p.size <= 0 {
+		return stats
+	}
+
+	ratio := min(float64(bytesRead)/float64(p.size), 1)
+	filled := int(ratio * widthBar)
+	eta := "--"
+
+	if throughput > 0 {
+		remain := time.Duration(float64(p.size-bytesRead) / throughput * float64(time.Second))
+		eta = max(remain, 0).Round(time.Second).String()
+	}
+
+	return fmt.Sprintf("[%s%s] %5.1f%% %s ETA %s",
+		strings.Repeat("=", filled), strings.Repeat(" ", widthBar-filled), ratio*100, stats, eta)
+}
+
+// formatBytes returns the human readable size in binary units.
+func formatBytes(size float64) string {
+	const unit = 1024
+
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB"}
+	index := 0
+
+	for size >= unit && index < len(units)-1 {
+		size /= unit
+		index++
+	}
+
+	return fmt.Sprintf("%.1f %s", size, units[index])
+}
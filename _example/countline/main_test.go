@@ -1,9 +1,14 @@
 package main
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
@@ -57,7 +62,8 @@ func Test_main_missing_args(t *testing.T) {
 		panic("forced panic")
 	}
 
-	os.Args = []string{t.Name()}
+	// The mode of a single file requires the path
+	os.Args = []string{t.Name(), "--follow"}
 
 	out := capturer.CaptureStderr(func() {
 		require.Panics(t, func() {
@@ -72,6 +78,589 @@ func Test_main_missing_args(t *testing.T) {
 	require.Equal(t, 1, capturedCode, "exit code should be 1 on error")
 }
 
+//nolint:paralleltest // do not parallelize due to temporary changing global variables
+func Test_main_progress(t *testing.T) {
+	oldOsArgs := os.Args
+	oldOsExit := osExit
+	oldInterval := progressInterval
+
+	defer func() {
+		os.Args = oldOsArgs
+		osExit = oldOsExit
+		progressInterval = oldInterval
+	}()
+
+	// Mock os.Exit() to capture the exit code
+	capturedCode := 0
+	osExit = func(code int) {
+		capturedCode = code
+	}
+
+	progressInterval = time.Nanosecond // update on every read
+
+	pathData := filepath.Join("..", "..", "cl", "testdata", "data_Small.txt")
+	expect := "88307"
+
+	os.Args = []string{t.Name(), "--progress", pathData}
+
+	var stdout string
+
+	stderr := capturer.CaptureStderr(func() {
+		stdout = capturer.CaptureStdout(func() {
+			main()
+		})
+	})
+
+	require.Equal(t, expect+" "+pathData+"\n", stdout, "STDOUT should contain the number of lines and the path")
+	require.Contains(t, stderr, "100.0%", "STDERR should contain the progress bar")
+	require.Contains(t, stderr, "lines: "+expect, "STDERR should end with the final number of lines")
+	require.Equal(t, 0, capturedCode, "exit code should be 0")
+}
+
+//nolint:paralleltest // do not parallelize due to temporary changing global variables
+func Test_main_progress_missing_file(t *testing.T) {
+	oldOsArgs := os.Args
+	oldOsExit := osExit
+
+	defer func() {
+		os.Args = oldOsArgs
+		osExit = oldOsExit
+	}()
+
+	// Mock os.Exit() to capture the exit code and panic instead of exiting
+	capturedCode := 0
+	osExit = func(code int) {
+		capturedCode = code
+
+		panic("forced panic")
+	}
+
+	pathFile := filepath.Join(t.TempDir(), "missing.txt")
+
+	os.Args = []string{t.Name(), "--progress", pathFile}
+
+	out := capturer.CaptureStderr(func() {
+		require.Panics(t, func() {
+			main()
+		})
+	})
+
+	require.Contains(t, out, "countline: "+pathFile+": failed to open file", "STDERR should contain the error reason")
+	require.NotContains(t, out, "Usage:", "help should not be printed on the error of a file")
+	require.Equal(t, 1, capturedCode, "exit code should be 1 on error")
+}
+
+//nolint:paralleltest // do not parallelize due to temporary changing global variables
+func Test_main_follow_missing_file(t *testing.T) {
+	oldOsArgs := os.Args
+	oldOsExit := osExit
+
+	defer func() {
+		os.Args = oldOsArgs
+		osExit = oldOsExit
+	}()
+
+	// Mock os.Exit() to capture the exit code and panic instead of exiting
+	capturedCode := 0
+	osExit = func(code int) {
+		capturedCode = code
+
+		panic("forced panic")
+	}
+
+	os.Args = []string{t.Name(), "--follow", filepath.Join(t.TempDir(), "missing.log")}
+
+	out := capturer.CaptureStderr(func() {
+		require.Panics(t, func() {
+			main()
+		})
+	})
+
+	require.Contains(t, out, "error: failed to open file", "STDERR should contain the error reason")
+	require.Equal(t, 1, capturedCode, "exit code should be 1 on error")
+}
+
+//nolint:paralleltest // do not parallelize due to temporary changing global variables
+func Test_main_unknown_flag(t *testing.T) {
+	oldOsArgs := os.Args
+	oldOsExit := osExit
+
+	defer func() {
+		os.Args = oldOsArgs
+		osExit = oldOsExit
+	}()
+
+	// Mock os.Exit() to capture the exit code and panic instead of exiting
+	capturedCode := 0
+	osExit = func(code int) {
+		capturedCode = code
+
+		panic("forced panic")
+	}
+
+	os.Args = []string{t.Name(), "--unknown", "foo.txt"}
+
+	out := capturer.CaptureStderr(func() {
+		require.Panics(t, func() {
+			main()
+		})
+	})
+
+	require.Contains(t, out, "flag provided but not defined: -unknown", "STDERR should contain the error reason")
+	require.Equal(t, 1, capturedCode, "exit code should be 1 on error")
+}
+
+//nolint:paralleltest // do not parallelize due to temporary changing global variables
+func Test_main_multiple_files(t *testing.T) {
+	oldOsArgs := os.Args
+	oldOsExit := osExit
+
+	defer func() {
+		os.Args = oldOsArgs
+		osExit = oldOsExit
+	}()
+
+	// Mock os.Exit() to capture the exit code
+	capturedCode := 0
+	osExit = func(code int) {
+		capturedCode = code
+	}
+
+	dirTemp := t.TempDir()
+	pathFoo := filepath.Join(dirTemp, "foo.txt")
+	pathBar := filepath.Join(dirTemp, "bar.txt.gz")
+	pathMissing := filepath.Join(dirTemp, "missing.txt")
+
+	require.NoError(t, os.WriteFile(pathFoo, []byte(strings.Repeat("foo\n", 12)), 0o600))
+	require.NoError(t, os.WriteFile(pathBar, gzipData(t, "bar\n"), 0o600))
+
+	os.Args = []string{t.Name(), pathFoo, pathMissing, pathBar}
+
+	var stdout string
+
+	stderr := capturer.CaptureStderr(func() {
+		stdout = capturer.CaptureStdout(func() {
+			main()
+		})
+	})
+
+	expect := "12 " + pathFoo + "\n" +
+		" 1 " + pathBar + "\n" +
+		"13 total\n"
+
+	require.Equal(t, expect, stdout, "missing file should be skipped")
+	require.Contains(t, stderr, "countline: "+pathMissing+": failed to open file")
+	require.Equal(t, 1, capturedCode, "exit code should be 1 if any file failed")
+}
+
+//nolint:paralleltest // do not parallelize due to temporary changing global variables
+func Test_main_format(t *testing.T) {
+	oldOsArgs := os.Args
+	oldOsExit := osExit
+
+	defer func() {
+		os.Args = oldOsArgs
+		osExit = oldOsExit
+	}()
+
+	// Mock os.Exit() to capture the exit code
+	capturedCode := 0
+	osExit = func(code int) {
+		capturedCode = code
+	}
+
+	dirTemp := t.TempDir()
+	pathFoo := filepath.Join(dirTemp, "foo.txt")
+	pathBar := filepath.Join(dirTemp, "bar.txt.gz")
+	pathMissing := filepath.Join(dirTemp, "missing.txt")
+	dataBar := gzipData(t, "bar\n")
+
+	require.NoError(t, os.WriteFile(pathFoo, []byte(strings.Repeat("foo\n", 12)), 0o600))
+	require.NoError(t, os.WriteFile(pathBar, dataBar, 0o600))
+
+	os.Args = []string{t.Name(), "--format", "json", pathFoo, pathMissing, pathBar}
+
+	var stdout string
+
+	stderr := capturer.CaptureStderr(func() {
+		stdout = capturer.CaptureStdout(func() {
+			main()
+		})
+	})
+
+	var actual summary
+
+	require.NoError(t, json.Unmarshal([]byte(stdout), &actual), "output should be a JSON document")
+	require.Equal(t, schemaVersion, actual.Version)
+	require.Len(t, actual.Files, 3, "failed file should be included")
+
+	require.Equal(t, pathFoo, actual.Files[0].Path)
+	require.Equal(t, 12, actual.Files[0].Lines)
+	require.Equal(t, int64(48), actual.Files[0].Bytes)
+	require.Nil(t, actual.Files[0].Error)
+
+	require.Equal(t, pathMissing, actual.Files[1].Path)
+	require.NotNil(t, actual.Files[1].Error)
+	require.Contains(t, *actual.Files[1].Error, "failed to open file")
+
+	require.Equal(t, 1, actual.Files[2].Lines)
+	require.Equal(t, int64(len(dataBar)), actual.Files[2].Bytes, "bytes should be the size of the compressed file")
+
+	require.Equal(t, 13, actual.Total.Lines)
+	require.Equal(t, int64(48+len(dataBar)), actual.Total.Bytes)
+
+	require.Contains(t, stderr, "countline: "+pathMissing+": failed to open file",
+		"errors should be reported to STDERR as well")
+	require.Equal(t, 1, capturedCode, "exit code should be 1 if any file failed")
+}
+
+//nolint:paralleltest // do not parallelize due to temporary changing global variables
+func Test_main_stdin(t *testing.T) {
+	oldOsArgs := os.Args
+	oldOsExit := osExit
+	oldOsStdin := osStdin
+
+	defer func() {
+		os.Args = oldOsArgs
+		osExit = oldOsExit
+		osStdin = oldOsStdin
+	}()
+
+	// Mock os.Exit() to capture the exit code
+	capturedCode := 0
+	osExit = func(code int) {
+		capturedCode = code
+	}
+
+	dirTemp := t.TempDir()
+	pathFile := filepath.Join(dirTemp, "foo.txt")
+
+	require.NoError(t, os.WriteFile(pathFile, []byte("foo\nbar\n"), 0o600))
+
+	for _, test := range []struct {
+		stdin  []byte
+		expect string
+		args   []string
+	}{
+		{args: []string{}, stdin: []byte("a\nb\nc\n"), expect: "3\n"},
+		{args: []string{"-"}, stdin: []byte("a\nb\nc"), expect: "3\n"},
+		{args: []string{"-"}, stdin: gzipData(t, "a\nb\n"), expect: "2\n"},
+		{args: []string{"--progress"}, stdin: []byte("a\n"), expect: "1\n"},
+		{args: []string{"-e", "b"}, stdin: []byte("a\nb\nc\n"), expect: "1\n"},
+		{args: []string{pathFile, "-"}, stdin: []byte("a\n"), expect: "2 " + pathFile + "\n1\n3 total\n"},
+	} {
+		pathStdinFile := filepath.Join(dirTemp, "stdin")
+		require.NoError(t, os.WriteFile(pathStdinFile, test.stdin, 0o600))
+
+		stdinFile, err := os.Open(pathStdinFile)
+		require.NoError(t, err)
+
+		osStdin = stdinFile
+		os.Args = append([]string{t.Name()}, test.args...)
+
+		var stdout string
+
+		_ = capturer.CaptureStderr(func() {
+			stdout = capturer.CaptureStdout(func() {
+				main()
+			})
+		})
+
+		require.NoError(t, stdinFile.Close())
+		require.Equal(t, test.expect, stdout, "args: %v", test.args)
+		require.Equal(t, 0, capturedCode, "exit code should be 0")
+	}
+}
+
+func Test_parseArgs(t *testing.T) {
+	t.Parallel()
+
+	conf, err := parseArgs([]string{})
+
+	require.NoError(t, err)
+	require.Equal(t, []string{"-"}, conf.paths, "no path should be STDIN")
+
+	conf, err = parseArgs([]string{"-v", "-e", "", "a.txt", "b.txt"})
+
+	require.NoError(t, err)
+	require.True(t, conf.matching, "empty pattern should be set")
+	require.True(t, conf.invert)
+	require.Equal(t, []string{"a.txt", "b.txt"}, conf.paths)
+
+	for _, args := range [][]string{
+		{"-r"},
+		{"-r", "a", "b"},
+		{"--archive"},
+		{"--follow", "a.log", "b.log"},
+	} {
+		_, err := parseArgs(args)
+
+		require.ErrorContains(t, err, "invalid number of arguments", "args: %v", args)
+	}
+
+	_, err = parseArgs([]string{"--unknown"})
+	require.ErrorContains(t, err, "failed to parse arguments")
+
+	conf, err = parseArgs([]string{"--format", "ndjson", "a.txt"})
+
+	require.NoError(t, err)
+	require.Equal(t, formatNDJSON, conf.format)
+
+	_, err = parseArgs([]string{"--format", "xml"})
+	require.ErrorContains(t, err, `unknown format: "xml"`)
+
+	for _, args := range [][]string{
+		{"--format", "json", "-r", "dir"},
+		{"--format", "csv", "--archive", "a.zip"},
+		{"--format", "tsv", "--follow", "a.log"},
+	} {
+		_, err := parseArgs(args)
+
+		require.ErrorContains(t, err, "--format is not supported", "args: %v", args)
+	}
+
+	conf, err = parseArgs([]string{"--strategy", "byteloop", "a.txt"})
+
+	require.NoError(t, err)
+	require.NotNil(t, conf.strategy, "strategy should be looked up")
+
+	_, err = parseArgs([]string{"--strategy", "unknown"})
+	require.ErrorContains(t, err, `unknown strategy: "unknown" (available: byteloop, default, fanout,`)
+
+	for _, args := range [][]string{
+		{"--strategy", "byteloop", "-r", "dir"},
+		{"--strategy", "byteloop", "-e", "foo"},
+		{"--strategy", "byteloop", "--progress"},
+	} {
+		_, err := parseArgs(args)
+
+		require.ErrorContains(t, err, "--strategy is not supported", "args: %v", args)
+	}
+}
+
+func Test_countWithStrategy(t *testing.T) {
+	t.Parallel()
+
+	dirTemp := t.TempDir()
+	pathGzip := filepath.Join(dirTemp, "app.log.gz")
+	require.NoError(t, os.WriteFile(pathGzip, gzipData(t, "foo\nbar\nbuzz"), 0o600))
+
+	for _, name := range []string{"default", "byteloop", "fanout", "readline", "scanner", "transform"} {
+		conf, err := parseArgs([]string{"--strategy", name, pathGzip})
+		require.NoError(t, err)
+
+		actual, err := conf.counter()(openInput(t, pathGzip))
+
+		require.NoError(t, err, name)
+		require.Equal(t, 3, actual, "compressed file should be decompressed with strategy: %s", name)
+	}
+
+	conf, err := parseArgs([]string{"--strategy", "byteloop"})
+	require.NoError(t, err)
+
+	_, err = conf.counter()(openInput(t, dirTemp))
+	require.ErrorContains(t, err, "failed to read file", "directory should not be counted")
+}
+
+//nolint:paralleltest // do not parallelize due to changing the working directory
+func Test_countFiles(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	for _, name := range []string{"a", "bbbbbbbbbb", "bad"} {
+		require.NoError(t, os.WriteFile(name, []byte("foo\n"), 0o600))
+	}
+
+	errForced := errors.New("forced error")
+	count := func(input *inputFile) (int, error) {
+		if input.path == "bad" {
+			return 0, errForced
+		}
+
+		return len(input.path), nil
+	}
+
+	for _, test := range []struct {
+		expectOut    string
+		expectNotice string
+		paths        []string
+		expectOK     bool
+	}{
+		{paths: []string{"a"}, expectOut: "1 a\n", expectOK: true},
+		{paths: []string{"-"}, expectOut: "1\n", expectOK: true},
+		{paths: []string{"a", "bbbbbbbbbb"}, expectOut: " 1 a\n10 bbbbbbbbbb\n11 total\n", expectOK: true},
+		{paths: []string{"bad"}, expectNotice: "countline: bad: forced error\n"},
+		{paths: []string{"a", "bad"}, expectOut: "1 a\n1 total\n", expectNotice: "countline: bad: forced error\n"},
+		{
+			paths:        []string{"missing"},
+			expectNotice: "countline: missing: failed to open file: open missing: no such file or directory\n",
+		},
+	} {
+		output, notice := new(bytes.Buffer), new(bytes.Buffer)
+
+		require.Equal(t, test.expectOK, countFiles(output, notice, test.paths, formatText, count), "paths: %v", test.paths)
+		require.Equal(t, test.expectOut, output.String(), "paths: %v", test.paths)
+		require.Equal(t, test.expectNotice, notice.String(), "paths: %v", test.paths)
+	}
+
+	// Failed to write the results
+	notice := new(bytes.Buffer)
+
+	require.False(t, countFiles(failWriter{}, notice, []string{"a"}, formatJSON, count))
+	require.Contains(t, notice.String(), "countline: failed to write JSON")
+}
+
+func Test_inputFile(t *testing.T) {
+	t.Parallel()
+
+	pathFile := filepath.Join(t.TempDir(), "app.log")
+	require.NoError(t, os.WriteFile(pathFile, []byte("foo\nbar\n"), 0o600))
+
+	input, err := openFile(pathFile)
+	require.NoError(t, err)
+
+	require.Equal(t, int64(8), input.regularSize())
+	require.Equal(t, int64(8), input.size(), "regular file should be its size without reading")
+	require.NoError(t, input.Close())
+	require.Equal(t, int64(-1), input.regularSize(), "closed file should be unknown size")
+
+	// Pipes are the bytes read
+	reader, writer, err := os.Pipe()
+	require.NoError(t, err)
+
+	defer reader.Close()
+
+	go func() {
+		_, _ = writer.WriteString("foo\nbar")
+		_ = writer.Close()
+	}()
+
+	input = &inputFile{file: reader, path: pathStdin}
+
+	lines, err := countFile(input)
+
+	require.NoError(t, err)
+	require.Equal(t, 2, lines)
+	require.Equal(t, int64(-1), input.regularSize())
+	require.Equal(t, int64(7), input.size())
+	require.NoError(t, input.Close(), "STDIN should not be closed")
+
+	_, err = reader.Stat()
+	require.NoError(t, err, "STDIN should be left open")
+}
+
+func Test_countFile(t *testing.T) {
+	t.Parallel()
+
+	dirTemp := t.TempDir()
+	pathPlain := filepath.Join(dirTemp, "app.log")
+	pathGzip := filepath.Join(dirTemp, "app.log.1.gz")
+
+	require.NoError(t, os.WriteFile(pathPlain, []byte("foo\nbar\nbuzz"), 0o600))
+	require.NoError(t, os.WriteFile(pathGzip, gzipData(t, "foo\nbar\n"), 0o600))
+
+	// Plain text that looks like the headers of zlib and bzip2
+	pathZlibLike := filepath.Join(dirTemp, "formula.txt")
+	pathBzip2Like := filepath.Join(dirTemp, "bzh.txt")
+
+	require.NoError(t, os.WriteFile(pathZlibLike, []byte("x^2 + y\nfoo\n"), 0o600))
+	require.NoError(t, os.WriteFile(pathBzip2Like, []byte("BZh1 is not bzip2\nfoo"), 0o600))
+
+	for pathFile, expect := range map[string]int{
+		pathPlain:     3,
+		pathGzip:      2,
+		pathZlibLike:  2,
+		pathBzip2Like: 2,
+		filepath.Join("..", "..", "cl", "testdata", "lines200.txt.bz2"): 200,
+	} {
+		actual, err := countFile(openInput(t, pathFile))
+
+		require.NoError(t, err, pathFile)
+		require.Equal(t, expect, actual, pathFile)
+	}
+
+	_, err := openFile(filepath.Join(dirTemp, "missing.log"))
+	require.ErrorContains(t, err, "failed to open file")
+
+	_, err = countFile(openInput(t, dirTemp))
+	require.ErrorContains(t, err, "failed to read file", "directory should not be counted")
+
+	pathLZW := filepath.Join(dirTemp, "app.log.Z")
+	require.NoError(t, os.WriteFile(pathLZW, []byte("\x1f\x9d\x90foo"), 0o600))
+
+	_, err = countFile(openInput(t, pathLZW))
+	require.ErrorContains(t, err, "unsupported compression format", "LZW should not be counted as plain text")
+}
+
+//nolint:paralleltest // do not parallelize due to capturing STDERR
+func Test_countWithProgress_compressed(t *testing.T) {
+	pathFile := filepath.Join(t.TempDir(), "app.log.gz")
+	require.NoError(t, os.WriteFile(pathFile, gzipData(t, "foo\nbar\n"), 0o600))
+
+	var (
+		count int
+		err   error
+	)
+
+	stderr := capturer.CaptureStderr(func() {
+		count, err = countWithProgress(openInput(t, pathFile))
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+	require.NotContains(t, stderr, "%", "the ratio should not be shown for the compressed file")
+	require.Contains(t, stderr, "lines: 2")
+
+	stderr = capturer.CaptureStderr(func() {
+		_, err = countWithProgress(openInput(t, filepath.Dir(pathFile)))
+	})
+
+	require.ErrorContains(t, err, "failed to read file")
+	require.Empty(t, stderr)
+}
+
+func Test_progressBar_render(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		name    string
+		expect  string
+		size    int64
+		read    int64
+		lines   int64
+		elapsed time.Duration
+	}{
+		{
+			name:    "known size",
+			size:    4 * 1024 * 1024,
+			read:    1024 * 1024,
+			lines:   100,
+			elapsed: time.Second,
+			expect:  "[=======                       ]  25.0% 1.0 MiB 1.0 MiB/s lines: 100 ETA 3s",
+		},
+		{
+			name:   "known size before the first read",
+			size:   1024,
+			expect: "[                              ]   0.0% 0.0 B 0.0 B/s lines: 0 ETA --",
+		},
+		{
+			name:    "unknown size",
+			size:    -1,
+			read:    2048,
+			lines:   10,
+			elapsed: 2 * time.Second,
+			expect:  "2.0 KiB 1.0 KiB/s lines: 10",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			bar := newProgressBar(new(bytes.Buffer), test.size)
+
+			require.Equal(t, test.expect, bar.render(test.read, test.lines, test.elapsed))
+		})
+	}
+}
+
 //nolint:paralleltest // do not parallelize due to temporary changing global variables
 func TestExitOnError(t *testing.T) {
 	oldOsExit := osExit
@@ -94,3 +683,41 @@ func TestExitOnError(t *testing.T) {
 	require.Contains(t, out, "error: test error", "error reason should be printed to STDERR")
 	require.Contains(t, out, "Usage:", "help should be printed on error")
 }
+
+// ============================================================================
+//  Helpers
+// ============================================================================
+
+// gzipData returns the data compressed in gzip.
+func gzipData(t *testing.T, data string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	writer := gzip.NewWriter(&buf)
+
+	_, err := writer.Write([]byte(data))
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	return buf.Bytes()
+}
+
+// openInput opens the file to count. It is closed on the cleanup.
+func openInput(t *testing.T, pathFile string) *inputFile {
+	t.Helper()
+
+	input, err := openFile(pathFile)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = input.Close() })
+
+	return input
+}
+
+// failWriter is an io.Writer that always fails.
+type failWriter struct{}
+
+func (failWriter) Write([]byte) (int, error) {
+	return 0, errors.New("forced write error")
+}
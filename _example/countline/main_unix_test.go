@@ -0,0 +1,42 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+//  Tests
+// ============================================================================
+
+// The FIFO must be counted from the bytes already read to detect the compression
+// since it can not be reopened and read again.
+func Test_countFile_fifo(t *testing.T) {
+	t.Parallel()
+
+	const numLines = 100000 // larger than the bytes read to detect the compression
+
+	pathFIFO := filepath.Join(t.TempDir(), "lines.fifo")
+
+	require.NoError(t, syscall.Mkfifo(pathFIFO, 0o600))
+
+	errWrite := make(chan error, 1)
+
+	go func() {
+		// Opening the FIFO blocks until the reader opens it
+		errWrite <- os.WriteFile(pathFIFO, []byte(strings.Repeat("foo\n", numLines)), 0o600)
+	}()
+
+	actual, err := countFile(openInput(t, pathFIFO))
+
+	require.NoError(t, err)
+	require.NoError(t, <-errWrite)
+	require.Equal(t, numLines, actual)
+}
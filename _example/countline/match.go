@@ -0,0 +1,50 @@
+package main
+
+import (
+	"regexp"
+
+	"github.com/KEINOS/go-countline/cl"
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+//  countMatching
+// ----------------------------------------------------------------------------
+
+// countMatching counts the lines of the file that match the pattern, the same as
+// "grep -c". If invert is true, it counts the lines that do not match. The
+// compressed file is decompressed while counting.
+func countMatching(input *inputFile, pattern string, invert bool) (int, error) {
+	matcher, err := newMatcher(pattern, invert)
+	if err != nil {
+		return 0, err
+	}
+
+	decompressed, _, err := cl.Decompress(input)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to read file")
+	}
+
+	return cl.CountMatching(decompressed, matcher)
+}
+
+// newMatcher returns the matcher of the pattern. The pattern is a regular
+// expression. If it has no meta characters, the faster literal matcher is used.
+func newMatcher(pattern string, invert bool) (cl.Matcher, error) {
+	var matcher cl.Matcher = cl.Literal(pattern)
+
+	if regexp.QuoteMeta(pattern) != pattern {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid pattern")
+		}
+
+		matcher = re
+	}
+
+	if invert {
+		matcher = cl.Invert(matcher)
+	}
+
+	return matcher, nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/KEINOS/go-countline/cl"
+	"github.com/stretchr/testify/require"
+	"github.com/zenizh/go-capturer"
+)
+
+// ============================================================================
+//  Tests
+// ============================================================================
+
+func Test_countMatching(t *testing.T) {
+	t.Parallel()
+
+	pathFile := filepath.Join(t.TempDir(), "app.log")
+	require.NoError(t, os.WriteFile(pathFile, []byte("INFO a.b\nERROR foo\nERROR a_b\nINFO end"), 0o600))
+
+	for _, test := range []struct {
+		pattern string
+		expect  int
+		invert  bool
+	}{
+		{pattern: "ERROR", expect: 2},
+		{pattern: "ERROR", invert: true, expect: 2},
+		{pattern: "^INFO", expect: 2},
+		{pattern: "a.b", expect: 2},
+		{pattern: "", expect: 4},
+		{pattern: "", invert: true, expect: 0},
+	} {
+		actual, err := countMatching(openInput(t, pathFile), test.pattern, test.invert)
+
+		require.NoError(t, err)
+		require.Equal(t, test.expect, actual, "pattern: %q, invert: %v", test.pattern, test.invert)
+	}
+
+	pathGzip := filepath.Join(t.TempDir(), "app.log.gz")
+	require.NoError(t, os.WriteFile(pathGzip, gzipData(t, "INFO a.b\nERROR foo\n"), 0o600))
+
+	actual, err := countMatching(openInput(t, pathGzip), "ERROR", false)
+
+	require.NoError(t, err)
+	require.Equal(t, 1, actual, "compressed file should be decompressed")
+
+	_, err = countMatching(openInput(t, filepath.Dir(pathGzip)), "ERROR", false)
+	require.ErrorContains(t, err, "failed to read file")
+
+	_, err = countMatching(openInput(t, pathFile), "(ERROR", false)
+	require.ErrorContains(t, err, "invalid pattern")
+}
+
+func Test_newMatcher(t *testing.T) {
+	t.Parallel()
+
+	matcher, err := newMatcher("ERROR", false)
+
+	require.NoError(t, err)
+	require.IsType(t, cl.Literal(""), matcher, "pattern without meta characters should be a literal")
+
+	matcher, err = newMatcher("^ERROR", false)
+
+	require.NoError(t, err)
+	require.NotEqual(t, cl.Literal("^ERROR"), matcher, "pattern with meta characters should be a regexp")
+}
+
+//nolint:paralleltest // do not parallelize due to temporary changing global variables
+func Test_main_pattern(t *testing.T) {
+	oldOsArgs := os.Args
+	oldOsExit := osExit
+
+	defer func() {
+		os.Args = oldOsArgs
+		osExit = oldOsExit
+	}()
+
+	// Mock os.Exit() to capture the exit code and panic instead of exiting
+	capturedCode := 0
+	osExit = func(code int) {
+		capturedCode = code
+
+		panic("forced panic")
+	}
+
+	pathFile := filepath.Join(t.TempDir(), "app.log")
+	require.NoError(t, os.WriteFile(pathFile, []byte("INFO start\nERROR foo\nINFO end\n"), 0o600))
+
+	for _, test := range []struct {
+		expect string
+		args   []string
+	}{
+		{args: []string{"-e", "ERROR", pathFile}, expect: "1"},
+		{args: []string{"-v", "-e", "ERROR", pathFile}, expect: "2"},
+		{args: []string{"-e", "", pathFile}, expect: "3"},
+	} {
+		os.Args = append([]string{t.Name()}, test.args...)
+
+		out := capturer.CaptureStdout(func() {
+			main()
+		})
+
+		require.Equal(t, test.expect+" "+pathFile+"\n", out, "args: %v", test.args)
+		require.Equal(t, 0, capturedCode, "exit code should be 0")
+	}
+
+	// -v without -e
+	os.Args = []string{t.Name(), "-v", pathFile}
+
+	out := capturer.CaptureStderr(func() {
+		require.Panics(t, func() {
+			main()
+		})
+	})
+
+	require.Contains(t, out, "error: -v requires -e PATTERN", "STDERR should contain the error reason")
+	require.Equal(t, 1, capturedCode, "exit code should be 1 on error")
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/KEINOS/go-countline/cl/walk"
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+//  countTree
+// ----------------------------------------------------------------------------
+
+// countTree prints the number of lines of each file under the directory and the
+// total, like "wc -l". The files failed to count are reported to the notice and
+// the error is returned after printing the others.
+func countTree(output, notice io.Writer, root string, opts walk.Options) error {
+	result, err := walk.Count(root, opts)
+	if err != nil {
+		return errors.Wrap(err, "failed to count directory")
+	}
+
+	width := len(strconv.FormatUint(result.Total, 10))
+	numFailed := 0
+
+	for _, file := range result.Files {
+		if file.Err != nil {
+			fmt.Fprintf(notice, "countline: %v\n", file.Err)
+
+			numFailed++
+
+			continue
+		}
+
+		fmt.Fprintf(output, "%*d %s\n", width, file.Lines, file.Path)
+	}
+
+	fmt.Fprintf(output, "%*d total\n", width, result.Total)
+
+	if numFailed > 0 {
+		return errors.Errorf("failed to count %d file(s)", numFailed)
+	}
+
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+//  Type: patternList
+// ----------------------------------------------------------------------------
+
+// patternList is the flag.Value of the glob patterns which can be given more than
+// once, such as "--include '*.go' --include '*.md'".
+type patternList []string
+
+// String returns the patterns joined with commas.
+func (p *patternList) String() string {
+	return strings.Join(*p, ",")
+}
+
+// Set appends the pattern.
+func (p *patternList) Set(pattern string) error {
+	*p = append(*p, pattern)
+
+	return nil
+}
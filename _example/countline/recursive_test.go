@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/KEINOS/go-countline/cl/walk"
+	"github.com/stretchr/testify/require"
+	"github.com/zenizh/go-capturer"
+)
+
+// ============================================================================
+//  Tests
+// ============================================================================
+
+//nolint:paralleltest // do not parallelize due to temporary changing global variables
+func Test_main_recursive(t *testing.T) {
+	oldOsArgs := os.Args
+	oldOsExit := osExit
+
+	defer func() {
+		os.Args = oldOsArgs
+		osExit = oldOsExit
+	}()
+
+	// Mock os.Exit() to capture the exit code
+	capturedCode := 0
+	osExit = func(code int) {
+		capturedCode = code
+	}
+
+	root := writeTree(t, map[string]string{
+		".gitignore":      "*.log\n",
+		"app.log":         "ignored\n",
+		"main.go":         "package main\n\nfunc main() {}\n",
+		"main_test.go":    "package main\n",
+		"README.md":       "# README\n",
+		"vendor/lib.go":   "package lib\n",
+		"cmd/tool/foo.go": "package main\n",
+	})
+
+	os.Args = []string{
+		t.Name(), "-r", "--include", "*.go", "--include", "*.md", "--exclude", "vendor", "--exclude", "*_test.go", root,
+	}
+
+	out := capturer.CaptureOutput(func() {
+		main()
+	})
+
+	expect := "1 " + filepath.Join(root, "README.md") + "\n" +
+		"1 " + filepath.Join(root, "cmd", "tool", "foo.go") + "\n" +
+		"3 " + filepath.Join(root, "main.go") + "\n" +
+		"5 total\n"
+
+	require.Equal(t, expect, out)
+	require.Equal(t, 0, capturedCode, "exit code should be 0")
+}
+
+func Test_countTree(t *testing.T) {
+	t.Parallel()
+
+	root := writeTree(t, map[string]string{
+		"a.txt":     "a\n",
+		"sub/b.txt": "b\nb\nb\nb\nb\nb\nb\nb\nb\nb\n",
+	})
+
+	output, notice := new(bytes.Buffer), new(bytes.Buffer)
+
+	require.NoError(t, countTree(output, notice, root, walk.Options{}))
+	require.Equal(t,
+		" 1 "+filepath.Join(root, "a.txt")+"\n10 "+filepath.Join(root, "sub", "b.txt")+"\n11 total\n",
+		output.String(), "numbers should be right-aligned")
+	require.Empty(t, notice.String())
+
+	err := countTree(output, notice, filepath.Join(root, "missing"), walk.Options{})
+	require.ErrorContains(t, err, "failed to count directory")
+}
+
+func Test_countTree_unreadable_file(t *testing.T) {
+	t.Parallel()
+
+	if os.Geteuid() == 0 {
+		t.Skip("root can read any file")
+	}
+
+	root := writeTree(t, map[string]string{"a.txt": "a\n", "b.txt": "b\n"})
+	require.NoError(t, os.Chmod(filepath.Join(root, "a.txt"), 0o000))
+
+	output, notice := new(bytes.Buffer), new(bytes.Buffer)
+
+	err := countTree(output, notice, root, walk.Options{})
+
+	require.ErrorContains(t, err, "failed to count 1 file(s)")
+	require.Equal(t, "1 "+filepath.Join(root, "b.txt")+"\n1 total\n", output.String())
+	require.Contains(t, notice.String(), "failed to open file")
+}
+
+func Test_patternList(t *testing.T) {
+	t.Parallel()
+
+	var patterns patternList
+
+	require.NoError(t, patterns.Set("*.go"))
+	require.NoError(t, patterns.Set("*.md"))
+	require.Equal(t, patternList{"*.go", "*.md"}, patterns)
+	require.Equal(t, "*.go,*.md", patterns.String())
+}
+
+// ============================================================================
+//  Helpers
+// ============================================================================
+
+// writeTree creates the files under a temporary directory and returns it.
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	root := t.TempDir()
+
+	for name, data := range files {
+		pathFile := filepath.Join(root, filepath.FromSlash(name))
+
+		require.NoError(t, os.MkdirAll(filepath.Dir(pathFile), 0o700))
+		require.NoError(t, os.WriteFile(pathFile, []byte(data), 0o600))
+	}
+
+	return root
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/KEINOS/go-countline/cl/sloc"
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+//  sloc subcommand
+// ----------------------------------------------------------------------------
+
+// slocCountDir is a copy of sloc.CountDir() to be able to mock it in tests.
+var slocCountDir = sloc.CountDir
+
+// runSloc runs the "sloc" subcommand which prints the number of blank, comment
+// and code lines per language of the source files under the directory.
+//
+// The files failed to count are reported to the notice and the totals of the
+// others are printed as well, the same as "cloc".
+func runSloc(output, notice io.Writer, args []string) error {
+	if len(args) != 1 {
+		return errors.New("sloc requires a DIR argument")
+	}
+
+	result, err := slocCountDir(args[0])
+	if err != nil {
+		return errors.Wrap(err, "failed to count source lines")
+	}
+
+	for _, failed := range result.Failed {
+		fmt.Fprintf(notice, "countline: %v\n", failed.Err)
+	}
+
+	printSloc(output, result.Languages)
+
+	if len(result.Failed) > 0 {
+		return errors.Errorf("failed to count %d file(s)", len(result.Failed))
+	}
+
+	return nil
+}
+
+// formatSlocRow is the format of a row of the sloc table.
+const formatSlocRow = "%-25s%10v%15v%15v%14v\n"
+
+// printSloc prints the results as a table the same as "cloc".
+func printSloc(output io.Writer, results []sloc.LanguageResult) {
+	rule := strings.Repeat("-", 79)
+
+	fmt.Fprintln(output, rule)
+	fmt.Fprintf(output, formatSlocRow, "Language", "files", "blank", "comment", "code")
+	fmt.Fprintln(output, rule)
+
+	for _, result := range results {
+		fmt.Fprintf(output, formatSlocRow, result.Language, result.Files, result.Blank, result.Comment, result.Code)
+	}
+
+	sum := sloc.Sum(results)
+
+	fmt.Fprintln(output, rule)
+	fmt.Fprintf(output, formatSlocRow, "SUM:", sum.Files, sum.Blank, sum.Comment, sum.Code)
+	fmt.Fprintln(output, rule)
+}
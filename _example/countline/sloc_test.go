@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/KEINOS/go-countline/cl/sloc"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"github.com/zenizh/go-capturer"
+)
+
+// ============================================================================
+//  Tests
+// ============================================================================
+
+//nolint:paralleltest // do not parallelize due to temporary changing global variables
+func Test_main_sloc(t *testing.T) {
+	oldOsArgs := os.Args
+	oldOsExit := osExit
+
+	defer func() {
+		os.Args = oldOsArgs
+		osExit = oldOsExit
+	}()
+
+	// Mock os.Exit() to capture the exit code
+	capturedCode := 0
+	osExit = func(code int) {
+		capturedCode = code
+	}
+
+	os.Args = []string{t.Name(), "sloc", "."}
+
+	out := capturer.CaptureOutput(func() {
+		main()
+	})
+
+	require.Contains(t, out, "Go ", "output should contain the Go files of this directory")
+	require.Contains(t, out, "SUM:", "output should contain the total")
+	require.Equal(t, 0, capturedCode, "exit code should be 0")
+}
+
+func Test_runSloc(t *testing.T) {
+	t.Parallel()
+
+	dirTemp := t.TempDir()
+
+	require.NoError(t, os.WriteFile(filepath.Join(dirTemp, "main.go"),
+		[]byte("package main\n\n// main\nfunc main() {}\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dirTemp, "run.sh"),
+		[]byte("#!/bin/sh\necho\n"), 0o600))
+
+	output := new(bytes.Buffer)
+
+	require.NoError(t, runSloc(output, new(bytes.Buffer), []string{dirTemp}))
+
+	expect := `-------------------------------------------------------------------------------
+Language                      files          blank        comment          code
+-------------------------------------------------------------------------------
+Go                                1              1              1             2
+Shell                             1              0              1             1
+-------------------------------------------------------------------------------
+SUM:                              2              1              2             3
+-------------------------------------------------------------------------------
+`
+	require.Equal(t, expect, output.String())
+}
+
+func Test_runSloc_errors(t *testing.T) {
+	t.Parallel()
+
+	err := runSloc(new(bytes.Buffer), new(bytes.Buffer), nil)
+	require.ErrorContains(t, err, "sloc requires a DIR argument")
+
+	err = runSloc(new(bytes.Buffer), new(bytes.Buffer), []string{filepath.Join(t.TempDir(), "missing")})
+	require.ErrorContains(t, err, "failed to count source lines")
+}
+
+//nolint:paralleltest // do not parallelize due to temporary changing global variables
+func Test_runSloc_failed_file(t *testing.T) {
+	oldSlocCountDir := slocCountDir
+
+	defer func() {
+		slocCountDir = oldSlocCountDir
+	}()
+
+	// Mock the result with a file failed to count, such as a permission error
+	slocCountDir = func(root string) (*sloc.DirResult, error) {
+		return &sloc.DirResult{
+			Languages: []sloc.LanguageResult{{Language: "Go", Files: 1, Result: sloc.Result{Code: 1}}},
+			Failed:    []sloc.FileError{{Path: "locked.go", Err: errors.New("locked.go: permission denied")}},
+		}, nil
+	}
+
+	output := new(bytes.Buffer)
+	notice := new(bytes.Buffer)
+
+	err := runSloc(output, notice, []string{"."})
+
+	require.ErrorContains(t, err, "failed to count 1 file(s)")
+	require.Equal(t, "countline: locked.go: permission denied\n", notice.String(),
+		"failed file should be reported")
+	require.Contains(t, output.String(), "SUM:                              1",
+		"totals of the others should be printed")
+}
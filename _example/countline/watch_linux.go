@@ -0,0 +1,74 @@
+//go:build linux
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// inotifyMask is the events to watch in the directory of the followed file. The
+// creation and renames are watched to detect the rotation.
+const inotifyMask = syscall.IN_MODIFY | syscall.IN_ATTRIB | syscall.IN_CLOSE_WRITE |
+	syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO
+
+// inotifyWatcher is the watcher using inotify. It watches the directory of the
+// file instead of the file itself to detect the file replaced by rename.
+type inotifyWatcher struct {
+	events   *os.File
+	buf      []byte
+	interval time.Duration
+}
+
+// newWatcher returns the inotify watcher of the file. It falls back to polling if
+// inotify is not available, such as the limit of the watches is reached.
+func newWatcher(pathFile string, interval time.Duration) watcher {
+	fd, err := syscall.InotifyInit1(syscall.IN_NONBLOCK | syscall.IN_CLOEXEC)
+	if err != nil {
+		return pollWatcher{interval: interval}
+	}
+
+	if _, err := syscall.InotifyAddWatch(fd, filepath.Dir(pathFile), inotifyMask); err != nil {
+		_ = syscall.Close(fd)
+
+		return pollWatcher{interval: interval}
+	}
+
+	return &inotifyWatcher{
+		// The non-blocking file is registered to the runtime poller so that the
+		// read deadline works.
+		events:   os.NewFile(uintptr(fd), "inotify"),
+		buf:      make([]byte, os.Getpagesize()),
+		interval: interval,
+	}
+}
+
+// Wait blocks until any event occurs in the directory. It also returns after the
+// interval to check the context and to be safe from missed events, such as on
+// network file systems.
+func (w *inotifyWatcher) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := w.events.SetReadDeadline(time.Now().Add(w.interval)); err != nil {
+		return errors.Wrap(err, "failed to set deadline")
+	}
+
+	// The events are discarded. The file is checked on any event.
+	if _, err := w.events.Read(w.buf); err != nil && !errors.Is(err, os.ErrDeadlineExceeded) {
+		return errors.Wrap(err, "failed to read inotify events")
+	}
+
+	return nil
+}
+
+// Close stops watching.
+func (w *inotifyWatcher) Close() error {
+	return errors.Wrap(w.events.Close(), "failed to close inotify")
+}
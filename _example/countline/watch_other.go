@@ -0,0 +1,11 @@
+//go:build !linux
+
+package main
+
+import "time"
+
+// newWatcher returns the polling watcher since inotify is not available on this
+// platform.
+func newWatcher(_ string, interval time.Duration) watcher {
+	return pollWatcher{interval: interval}
+}
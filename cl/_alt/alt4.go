@@ -1,37 +1,18 @@
 package alt
 
 import (
-	"bufio"
 	"io"
 
-	"github.com/pkg/errors"
+	"github.com/KEINOS/go-countline/cl/strategy"
 )
 
-// CountLinesAlt4 uses atomic and goroutines to count the number of lines.
-func CountLinesAlt4(inputReader io.Reader) (int, error) {
-	if inputReader == nil {
-		return 0, errors.New("given reader is nil")
-	}
-
-	bufReader := bufio.NewReader(inputReader)
-	count := 0
-
-	for {
-		_, isPrefix, err := bufReader.ReadLine()
-		if err != nil {
-			if err == io.EOF {
-				break
-			}
-
-			return 0, errors.Wrap(err, "failed to read from reader")
-		}
+// ----------------------------------------------------------------------------
+//  CountLinesAlt4
+// ----------------------------------------------------------------------------
 
-		if isPrefix {
-			continue
-		}
-
-		count++
-	}
-
-	return count, nil
+// CountLinesAlt4 uses bufio.Reader.ReadLine to count the number of lines.
+//
+// It is registered as strategy.ReadLine in the cl/strategy package.
+func CountLinesAlt4(inputReader io.Reader) (int, error) {
+	return mustLookup(strategy.ReadLine).CountLines(inputReader)
 }
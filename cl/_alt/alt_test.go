@@ -72,6 +72,14 @@ func TestCountLines_specs(t *testing.T) {
 	}
 }
 
+func TestMustLookup(t *testing.T) {
+	t.Parallel()
+
+	require.PanicsWithValue(t, "strategy not registered: unknown", func() {
+		mustLookup("unknown")
+	})
+}
+
 // DummyReader is a dummy io.Reader that returns an error on Read().
 type DummyReader struct {
 	msg string
@@ -0,0 +1,120 @@
+package cl
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// inlineMaxSize is the maximum size of the input to be counted in the calling
+// goroutine. Below this, starting the goroutines costs more than they save.
+const inlineMaxSize = 4 * chunkSize
+
+// ----------------------------------------------------------------------------
+//  Type: Path
+// ----------------------------------------------------------------------------
+
+// Path is the way to count the input chosen at runtime by the type and the size
+// of the input. See Options.DebugHook to observe the choice.
+type Path int
+
+const (
+	// PathInline counts the input in a simple loop in the calling goroutine. It is
+	// chosen for the in-memory readers, such as *bytes.Reader and *strings.Reader,
+	// and for the inputs of a known size up to 256 KiB.
+	PathInline Path = iota + 1
+	// PathReaderAt counts the sections of the input in parallel via io.ReaderAt.
+	// It is chosen for the other inputs of a known size, such as regular files.
+	PathReaderAt
+	// PathStream reads the input sequentially and lets the workers count the
+	// chunks. It is chosen for the inputs of an unknown size, such as pipes.
+	PathStream
+)
+
+// String returns the name of the path.
+func (p Path) String() string {
+	switch p {
+	case PathInline:
+		return "inline"
+	case PathReaderAt:
+		return "readerat"
+	case PathStream:
+		return "stream"
+	}
+
+	return "unknown"
+}
+
+// choosePath returns the path to count the input of a known size. The size is of
+// the unread part of the input. The inputs of an unknown size are PathStream.
+func choosePath(inputReader io.Reader, size int64) Path {
+	switch inputReader.(type) {
+	case *bytes.Reader, *strings.Reader:
+		// Already in memory. Reading it is copying the memory which is faster
+		// than handing the chunks to the goroutines.
+		return PathInline
+	}
+
+	if size <= inlineMaxSize {
+		return PathInline
+	}
+
+	return PathReaderAt
+}
+
+// ----------------------------------------------------------------------------
+//  countInline
+// ----------------------------------------------------------------------------
+
+// countInline reads the input into a single buffer and counts the terminators in
+// the calling goroutine. On context cancellation, it returns the number of lines
+// counted so far along with ctx.Err().
+func countInline(ctx context.Context, inputReader io.Reader, opts Options) (uint64, error) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	term := opts.Terminator
+	rep := newReporter(opts)
+	trail := new(trailer)
+	count := uint64(0)
+	offset := int64(0)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return count, err
+		}
+
+		numRead, err := inputReader.Read(*buf)
+		if numRead > 0 {
+			chunk := (*buf)[:numRead]
+			found := term.count(chunk) + term.straddle(trail.tail, chunk)
+
+			count += found
+			offset += int64(numRead)
+
+			trail.push(chunk)
+			rep.addLines(found)
+			rep.addBytes(numRead)
+		}
+
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			//nolint:gosec // never exceeds in practice
+			return count, &ReadError{Offset: offset, Lines: int64(count), Err: err}
+		}
+	}
+
+	if opts.hasFragment(trail) {
+		count++
+	}
+
+	rep.done(count)
+
+	return count, nil
+}
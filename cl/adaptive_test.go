@@ -0,0 +1,205 @@
+package cl
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+
+	"github.com/KEINOS/go-countline/cl/spec"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+//  Tests
+// ============================================================================
+
+func TestPath_String(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "inline", PathInline.String())
+	require.Equal(t, "readerat", PathReaderAt.String())
+	require.Equal(t, "stream", PathStream.String())
+	require.Equal(t, "unknown", Path(0).String())
+}
+
+func Test_choosePath(t *testing.T) {
+	t.Parallel()
+
+	large := int64(inlineMaxSize + 1)
+
+	for _, test := range []struct {
+		input  io.Reader
+		name   string
+		size   int64
+		expect Path
+	}{
+		{name: "small strings.Reader", input: strings.NewReader(""), size: 1, expect: PathInline},
+		{name: "large strings.Reader", input: strings.NewReader(""), size: large, expect: PathInline},
+		{name: "large bytes.Reader", input: bytes.NewReader(nil), size: large, expect: PathInline},
+		{name: "small section", input: io.NewSectionReader(nil, 0, 0), size: inlineMaxSize, expect: PathInline},
+		{name: "large section", input: io.NewSectionReader(nil, 0, 0), size: large, expect: PathReaderAt},
+	} {
+		require.Equal(t, test.expect, choosePath(test.input, test.size), test.name)
+	}
+}
+
+func TestOptions_DebugHook(t *testing.T) {
+	t.Parallel()
+
+	type called struct {
+		path Path
+		size int64
+	}
+
+	dirTemp := t.TempDir()
+	pathSmall := filepath.Join(dirTemp, "small.txt")
+	pathLarge := filepath.Join(dirTemp, "large.txt")
+	dataLarge := strings.Repeat("0123456789abcde\n", inlineMaxSize/16+1)
+
+	require.NoError(t, os.WriteFile(pathSmall, []byte("foo\nbar\n"), 0o600))
+	require.NoError(t, os.WriteFile(pathLarge, []byte(dataLarge), 0o600))
+
+	for _, test := range []struct {
+		open   func() io.Reader
+		name   string
+		expect called
+	}{
+		{
+			name:   "strings.Reader",
+			open:   func() io.Reader { return strings.NewReader("foo\nbar") },
+			expect: called{path: PathInline, size: 7},
+		},
+		{
+			name:   "large bytes.Reader",
+			open:   func() io.Reader { return bytes.NewReader([]byte(dataLarge)) },
+			expect: called{path: PathInline, size: int64(len(dataLarge))},
+		},
+		{
+			name:   "small file",
+			open:   func() io.Reader { return openFile(t, pathSmall) },
+			expect: called{path: PathInline, size: 8},
+		},
+		{
+			name:   "large file",
+			open:   func() io.Reader { return openFile(t, pathLarge) },
+			expect: called{path: PathReaderAt, size: int64(len(dataLarge))},
+		},
+		{
+			name:   "stream",
+			open:   func() io.Reader { return iotest.HalfReader(strings.NewReader("foo\nbar")) },
+			expect: called{path: PathStream, size: -1},
+		},
+	} {
+		actual := []called{}
+
+		numLines, err := CountLinesWithOptions(test.open(), Options{
+			DebugHook: func(path Path, size int64) {
+				actual = append(actual, called{path: path, size: size})
+			},
+		})
+
+		require.NoError(t, err, test.name)
+		require.NotZero(t, numLines, test.name)
+		require.Equal(t, []called{test.expect}, actual, test.name)
+	}
+}
+
+// The hooks of the concurrent callers must not see the events of each other.
+func TestOptions_DebugHook_concurrent(t *testing.T) {
+	t.Parallel()
+
+	const numCallers = 8
+
+	var wg sync.WaitGroup
+
+	actual := make([][]int64, numCallers)
+	errs := make([]error, numCallers)
+
+	for index := range numCallers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			input := strings.NewReader(strings.Repeat("\n", index+1))
+
+			_, errs[index] = CountLinesWithOptions(input, Options{
+				DebugHook: func(_ Path, size int64) {
+					actual[index] = append(actual[index], size)
+				},
+			})
+		}()
+	}
+
+	wg.Wait()
+
+	for index := range numCallers {
+		require.NoError(t, errs[index], "caller: %d", index)
+		require.Equal(t, []int64{int64(index + 1)}, actual[index], "caller: %d", index)
+	}
+}
+
+func Test_countInline_golden(t *testing.T) {
+	t.Parallel()
+
+	spec.RunSpecTestTerminator(t, "countInline_one_byte", func(terminator string, r io.Reader) (int, error) {
+		// Read byte by byte to split every terminator across the reads
+		count, err := countInline(
+			context.Background(), iotest.OneByteReader(r), Options{Terminator: parseTerminator(t, terminator)},
+		)
+
+		return int(count), err //nolint:gosec // small number of lines in spec tests
+	})
+}
+
+func Test_countInline_read_fail(t *testing.T) {
+	t.Parallel()
+
+	errForced := errors.New("forced error")
+	input := io.MultiReader(strings.NewReader("foo\nbar\n"), iotest.ErrReader(errForced))
+
+	count, err := countInline(context.Background(), input, Options{}.normalize())
+
+	readErr := (*ReadError)(nil)
+
+	require.ErrorAs(t, err, &readErr)
+	require.ErrorIs(t, err, errForced)
+	require.Equal(t, int64(8), readErr.Offset)
+	require.Equal(t, int64(2), readErr.Lines)
+	require.Equal(t, uint64(2), count)
+}
+
+func Test_countInline_canceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	count, err := countInline(ctx, strings.NewReader("foo\n"), Options{}.normalize())
+
+	require.ErrorIs(t, err, context.Canceled)
+	require.Zero(t, count)
+}
+
+// ============================================================================
+//  Helpers
+// ============================================================================
+
+// openFile opens the file of the path. It is closed on the cleanup.
+func openFile(t *testing.T, pathFile string) *os.File {
+	t.Helper()
+
+	osFile, err := os.Open(pathFile)
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = osFile.Close() })
+
+	return osFile
+}
@@ -0,0 +1,253 @@
+/*
+Package archive counts the number of lines of each file in tar and zip archives
+without extracting them to the disk.
+*/
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"github.com/KEINOS/go-countline/cl"
+	"github.com/pkg/errors"
+)
+
+// sniffSize is the size of the head of an entry to detect binary files, the same
+// as git.
+const sniffSize = 8000
+
+// magicZip are the signatures at the beginning of zip files. The latter is of the
+// empty archive.
+//
+//nolint:gochecknoglobals // byte sequences of the magic bytes
+var magicZip = [][]byte{[]byte("PK\x03\x04"), []byte("PK\x05\x06")}
+
+// ----------------------------------------------------------------------------
+//  Type: Entry
+// ----------------------------------------------------------------------------
+
+// Entry is the number of lines of a file in the archive.
+type Entry struct {
+	// Path is the path of the file in the archive.
+	Path string
+	// Lines is the number of lines of the file, counted the same as
+	// cl.CountLines.
+	Lines uint64
+}
+
+// ----------------------------------------------------------------------------
+//  Type: Result
+// ----------------------------------------------------------------------------
+
+// Result is the number of lines of the files in the archive.
+type Result struct {
+	// Entries are the text files in the order of the archive.
+	Entries []Entry
+	// Skipped are the paths of the binary files which are not counted. A file is
+	// binary if its first 8000 bytes contain a NUL byte.
+	Skipped []string
+	// Total is the sum of the lines of the Entries.
+	Total uint64
+}
+
+// add appends the counted entry to the result.
+func (r *Result) add(path string, lines uint64, binary bool) {
+	if binary {
+		r.Skipped = append(r.Skipped, path)
+
+		return
+	}
+
+	r.Entries = append(r.Entries, Entry{Path: path, Lines: lines})
+	r.Total += lines
+}
+
+// ----------------------------------------------------------------------------
+//  CountFile
+// ----------------------------------------------------------------------------
+
+// CountFile counts the lines of the files in the archive file. The format is
+// detected by the magic bytes. Zip files are counted by CountZip and the others by
+// CountTar, which also accepts the compressed tar files such as ".tar.gz".
+func CountFile(pathFile string) (*Result, error) {
+	osFile, err := os.Open(filepath.Clean(pathFile))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open file")
+	}
+
+	defer osFile.Close()
+
+	info, err := osFile.Stat()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get file info")
+	}
+
+	head := make([]byte, len(magicZip[0]))
+
+	if _, err := osFile.ReadAt(head, 0); err == nil {
+		for _, magic := range magicZip {
+			if bytes.Equal(head, magic) {
+				return CountZip(osFile, info.Size())
+			}
+		}
+	}
+
+	return CountTar(osFile)
+}
+
+// ----------------------------------------------------------------------------
+//  CountTar
+// ----------------------------------------------------------------------------
+
+// CountTar counts the lines of the regular files in the tar archive. The archive
+// compressed in the formats supported by cl.Decompress, such as ".tar.gz" and
+// ".tar.bz2", is decompressed as well.
+//
+// Since the tar archive can only be read sequentially, the files are counted one
+// by one. Each file is still counted in parallel by cl.CountLines64.
+func CountTar(inputReader io.Reader) (*Result, error) {
+	decompressed, _, err := cl.Decompress(inputReader)
+	if err != nil {
+		return nil, err //nolint:wrapcheck // the error is already wrapped
+	}
+
+	archive := tar.NewReader(decompressed)
+	result := new(Result)
+
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			return result, nil
+		}
+
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read tar entry")
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue // directories, links and so on
+		}
+
+		lines, binary, err := countEntry(archive)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to count %s", header.Name)
+		}
+
+		result.add(header.Name, lines, binary)
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  CountZip
+// ----------------------------------------------------------------------------
+
+// CountZip counts the lines of the files in the zip archive of the given size.
+//
+// Unlike the tar archive, the files are counted in parallel since they can be read
+// independently.
+func CountZip(inputReaderAt io.ReaderAt, size int64) (*Result, error) {
+	if inputReaderAt == nil {
+		return nil, cl.ErrNilReader
+	}
+
+	archive, err := zip.NewReader(inputReaderAt, size)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read zip archive")
+	}
+
+	files := make([]*zip.File, 0, len(archive.File))
+
+	for _, file := range archive.File {
+		if file.Mode().IsRegular() {
+			files = append(files, file)
+		}
+	}
+
+	type counted struct {
+		err    error
+		lines  uint64
+		binary bool
+	}
+
+	results := make([]counted, len(files))
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+
+	for range min(runtime.GOMAXPROCS(0), max(len(files), 1)) {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for index := range indexes {
+				res := &results[index]
+				res.lines, res.binary, res.err = countZipFile(files[index])
+			}
+		}()
+	}
+
+	for index := range files {
+		indexes <- index
+	}
+
+	close(indexes)
+	wg.Wait()
+
+	result := new(Result)
+
+	for index, file := range files {
+		if err := results[index].err; err != nil {
+			return nil, errors.Wrapf(err, "failed to count %s", file.Name)
+		}
+
+		result.add(file.Name, results[index].lines, results[index].binary)
+	}
+
+	return result, nil
+}
+
+// countZipFile counts the lines of the file in the zip archive.
+func countZipFile(file *zip.File) (uint64, bool, error) {
+	entry, err := file.Open()
+	if err != nil {
+		return 0, false, errors.Wrap(err, "failed to open zip entry")
+	}
+
+	defer entry.Close()
+
+	return countEntry(entry)
+}
+
+// ----------------------------------------------------------------------------
+//  countEntry
+// ----------------------------------------------------------------------------
+
+// countEntry counts the lines of the entry. It returns true without counting if
+// the entry is binary.
+func countEntry(entry io.Reader) (uint64, bool, error) {
+	buffered := bufio.NewReaderSize(entry, sniffSize)
+
+	head, err := buffered.Peek(sniffSize)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return 0, false, errors.Wrap(err, "failed to read entry")
+	}
+
+	if bytes.IndexByte(head, 0) >= 0 {
+		return 0, true, nil
+	}
+
+	lines, err := cl.CountLines64(buffered)
+	if err != nil {
+		return 0, false, err //nolint:wrapcheck // wrapped by the caller
+	}
+
+	return lines, false, nil
+}
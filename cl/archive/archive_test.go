@@ -0,0 +1,269 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/KEINOS/go-countline/cl"
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+//  Tests
+// ============================================================================
+
+func TestCountTar(t *testing.T) {
+	t.Parallel()
+
+	archive := makeTar(t, sampleFiles)
+
+	for name, input := range map[string][]byte{
+		"tar":    archive,
+		"tar.gz": gzipData(t, archive),
+	} {
+		result, err := CountTar(bytes.NewReader(input))
+
+		require.NoError(t, err, name)
+		require.Equal(t, sampleResult, result, name)
+	}
+}
+
+func TestCountTar_errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := CountTar(nil)
+	require.ErrorIs(t, err, cl.ErrNilReader)
+
+	_, err = CountTar(strings.NewReader(strings.Repeat("not a tar archive\n", 100)))
+	require.ErrorContains(t, err, "failed to read tar entry")
+
+	// Truncated in the middle of the file contents
+	archive := makeTar(t, []file{{name: "foo.txt", data: strings.Repeat("foo\n", 1000)}})
+
+	_, err = CountTar(bytes.NewReader(archive[:1024]))
+	require.ErrorContains(t, err, "failed to count foo.txt")
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+func TestCountZip(t *testing.T) {
+	t.Parallel()
+
+	archive := makeZip(t, sampleFiles)
+
+	result, err := CountZip(bytes.NewReader(archive), int64(len(archive)))
+
+	require.NoError(t, err)
+	require.Equal(t, sampleResult, result)
+}
+
+func TestCountZip_many_entries(t *testing.T) {
+	t.Parallel()
+
+	files := []file{}
+	expect := &Result{}
+
+	for i := range 100 {
+		data := strings.Repeat("line\n", i)
+		name := fmt.Sprintf("dir/file%03d.txt", i)
+
+		files = append(files, file{name: name, data: data})
+		expect.add(name, uint64(i), false) //nolint:gosec // never negative
+	}
+
+	archive := makeZip(t, files)
+
+	result, err := CountZip(bytes.NewReader(archive), int64(len(archive)))
+
+	require.NoError(t, err)
+	require.Equal(t, expect, result, "entries should be in the order of the archive")
+	require.Equal(t, uint64(4950), result.Total)
+}
+
+func TestCountZip_errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := CountZip(nil, 0)
+	require.ErrorIs(t, err, cl.ErrNilReader)
+
+	_, err = CountZip(strings.NewReader("not a zip archive"), 17)
+	require.ErrorContains(t, err, "failed to read zip archive")
+
+	// Broken contents of the entry
+	archive := makeZip(t, []file{{name: "foo.txt", data: "foo\nbar\n"}, {name: "bar.txt", data: "bar\n"}})
+	archive = bytes.Replace(archive, []byte("foo\nbar\n"), []byte("foo\nbuz\n"), 1)
+
+	_, err = CountZip(bytes.NewReader(archive), int64(len(archive)))
+	require.ErrorContains(t, err, "failed to count foo.txt")
+	require.ErrorIs(t, err, zip.ErrChecksum)
+
+	// Unknown compression method
+	archive = makeZip(t, []file{{name: "foo.txt", data: "foo\n"}})
+	archive[8] = 0x63 // method of the local file header
+	archive[bytes.LastIndex(archive, []byte("PK\x01\x02"))+10] = 0x63
+
+	_, err = CountZip(bytes.NewReader(archive), int64(len(archive)))
+	require.ErrorContains(t, err, "failed to open zip entry")
+}
+
+func TestCountFile(t *testing.T) {
+	t.Parallel()
+
+	dirTemp := t.TempDir()
+	tarFile := makeTar(t, sampleFiles)
+
+	for name, data := range map[string][]byte{
+		"bundle.zip":    makeZip(t, sampleFiles),
+		"bundle.tar":    tarFile,
+		"bundle.tar.gz": gzipData(t, tarFile),
+	} {
+		pathFile := filepath.Join(dirTemp, name)
+		require.NoError(t, os.WriteFile(pathFile, data, 0o600))
+
+		result, err := CountFile(pathFile)
+
+		require.NoError(t, err, name)
+		require.Equal(t, sampleResult, result, name)
+	}
+
+	// Empty archives
+	for name, data := range map[string][]byte{
+		"empty.zip": makeZip(t, nil),
+		"empty.tar": makeTar(t, nil),
+	} {
+		pathFile := filepath.Join(dirTemp, name)
+		require.NoError(t, os.WriteFile(pathFile, data, 0o600))
+
+		result, err := CountFile(pathFile)
+
+		require.NoError(t, err, name)
+		require.Equal(t, &Result{}, result, name)
+	}
+
+	_, err := CountFile(filepath.Join(dirTemp, "missing.zip"))
+	require.ErrorContains(t, err, "failed to open file")
+}
+
+func Test_countEntry(t *testing.T) {
+	t.Parallel()
+
+	lines, binary, err := countEntry(strings.NewReader(strings.Repeat("a", sniffSize) + "\x00"))
+
+	require.NoError(t, err)
+	require.False(t, binary, "NUL after the sniffed head should not be detected")
+	require.Equal(t, uint64(1), lines)
+
+	_, _, err = countEntry(iotest.ErrReader(io.ErrClosedPipe))
+	require.ErrorContains(t, err, "failed to read entry")
+}
+
+// ============================================================================
+//  Helpers
+// ============================================================================
+
+type file struct {
+	name string
+	data string
+	dir  bool
+}
+
+// sampleFiles are the files of the sample archive and sampleResult is the result
+// of counting it.
+//
+//nolint:gochecknoglobals // read-only test data
+var (
+	sampleFiles = []file{
+		{name: "dir/", dir: true},
+		{name: "dir/foo.txt", data: "foo\nbar\n"},
+		{name: "dir/image.png", data: "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"},
+		{name: "README.md", data: "# title\n\ndescription"},
+		{name: "empty.txt", data: ""},
+		{name: "large.log", data: strings.Repeat("a line of log\n", 100000)},
+	}
+	sampleResult = &Result{
+		Entries: []Entry{
+			{Path: "dir/foo.txt", Lines: 2},
+			{Path: "README.md", Lines: 3},
+			{Path: "empty.txt", Lines: 0},
+			{Path: "large.log", Lines: 100000},
+		},
+		Skipped: []string{"dir/image.png"},
+		Total:   100005,
+	}
+)
+
+func makeTar(t *testing.T, files []file) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	writer := tar.NewWriter(&buf)
+
+	for _, file := range files {
+		header := &tar.Header{Name: file.name, Mode: 0o600, Size: int64(len(file.data)), Typeflag: tar.TypeReg}
+		if file.dir {
+			header.Typeflag = tar.TypeDir
+		}
+
+		require.NoError(t, writer.WriteHeader(header))
+
+		_, err := writer.Write([]byte(file.data))
+		require.NoError(t, err)
+	}
+
+	// A symbolic link should be skipped
+	require.NoError(t, writer.WriteHeader(&tar.Header{Name: "link", Linkname: "README.md", Typeflag: tar.TypeSymlink}))
+	require.NoError(t, writer.Close())
+
+	return buf.Bytes()
+}
+
+func makeZip(t *testing.T, files []file) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	writer := zip.NewWriter(&buf)
+
+	for _, file := range files {
+		if file.dir {
+			_, err := writer.Create(file.name)
+			require.NoError(t, err)
+
+			continue
+		}
+
+		// Store without compression to be able to break the contents in tests
+		entry, err := writer.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Store})
+		require.NoError(t, err)
+
+		_, err = entry.Write([]byte(file.data))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, writer.Close())
+
+	return buf.Bytes()
+}
+
+func gzipData(t *testing.T, data []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	writer := gzip.NewWriter(&buf)
+
+	_, err := writer.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	return buf.Bytes()
+}
@@ -0,0 +1,52 @@
+package archive_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"log"
+
+	"github.com/KEINOS/go-countline/cl/archive"
+)
+
+func ExampleCountZip() {
+	var buf bytes.Buffer
+
+	writer := zip.NewWriter(&buf)
+
+	for _, file := range []struct{ name, data string }{
+		{"README.md", "# Title\n\nHello, world!\n"},
+		{"app.log", "foo\nbar\nbuzz"},
+		{"icon.png", "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"},
+	} {
+		entry, err := writer.Create(file.name)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if _, err := entry.Write([]byte(file.data)); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		log.Fatal(err)
+	}
+
+	result, err := archive.CountZip(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, entry := range result.Entries {
+		fmt.Println(entry.Lines, entry.Path)
+	}
+
+	fmt.Println(result.Total, "total")
+	fmt.Println("skipped:", result.Skipped)
+	// Output:
+	// 3 README.md
+	// 3 app.log
+	// 6 total
+	// skipped: [icon.png]
+}
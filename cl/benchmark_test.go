@@ -42,3 +42,27 @@ func BenchmarkCountLines(b *testing.B) {
 		)
 	}
 }
+
+func BenchmarkCountLinesFile(b *testing.B) {
+	// 1 GiB size file
+	pathFile := filepath.Clean(filepath.Join("testdata", "data_Giant.txt"))
+
+	expectNumLines := 72323529
+
+	b.ResetTimer() // Begin benchmark
+
+	// Run function
+	actualNumLines, err := cl.CountLinesFile(pathFile)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.StopTimer() // End benchmark
+
+	if expectNumLines != actualNumLines {
+		b.Fatalf(
+			"test %v failed: expect=%d, actual=%d",
+			b.Name(), expectNumLines, actualNumLines,
+		)
+	}
+}
@@ -2,94 +2,309 @@ package cl
 
 import (
 	"bufio"
-	"bytes"
+	"context"
 	"io"
+	"runtime"
 	"sync"
 	"sync/atomic"
 
 	"github.com/pkg/errors"
 )
 
+// maxInt is the maximum possitive value of int on current system in uint.
+const maxInt = ^uint(0) >> 1
+
+// chunkSize is the fixed size of the buffers to read the input into.
+const chunkSize = bufio.MaxScanTokenSize
+
+// bufPool is a pool of chunkSize-length buffers to be reused between reads.
+var bufPool = sync.Pool{
+	New: func() any {
+		buf := make([]byte, chunkSize)
+
+		return &buf
+	},
+}
+
+// ----------------------------------------------------------------------------
+//  CountLines
+// ----------------------------------------------------------------------------
+
 // CountLines counts the number of lines that contains a line break (LF) in a file.
 //
-//nolint:funlen,cyclop // only exceeds 4 lines(74/70), complexity of 1 cycle(11/19)
+// The way to count is chosen at runtime by the type and the size of the input.
+// Small and in-memory inputs are counted in a simple loop, regular files are
+// counted by sections in parallel and pipes are streamed to the workers. See Path
+// and Options.DebugHook for the details.
+//
+// It returns an error if the number of lines exceeds the maximum value of int,
+// such as on 32bit systems. Use CountLines64 to count beyond that.
 func CountLines(inputReader io.Reader) (int, error) {
-	// Current implementation is alt6.go
+	count, err := CountLines64(inputReader)
+	if err != nil {
+		return 0, err
+	}
 
-	// maxInt is the maximum possitive value of int on current system in uint.
-	const maxInt = ^uint(0) >> 1
+	return toInt(count)
+}
 
-	if inputReader == nil {
-		return 0, errors.New("given reader is nil")
+// ----------------------------------------------------------------------------
+//  CountLines64
+// ----------------------------------------------------------------------------
+
+// CountLines64 is the same as CountLines but returns the number of lines as
+// uint64. It never overflows even on 32bit systems.
+func CountLines64(inputReader io.Reader) (uint64, error) {
+	return CountLinesContext64(context.Background(), inputReader)
+}
+
+// ----------------------------------------------------------------------------
+//  CountLinesContext
+// ----------------------------------------------------------------------------
+
+// CountLinesContext is the same as CountLines but stops counting once the given
+// context is canceled or its deadline is exceeded.
+//
+// The context is checked between each chunk read. On cancellation, it waits for
+// the already started goroutines to finish and returns the number of lines
+// counted so far along with ctx.Err().
+//
+// The way to count is chosen by the type and the size of the input. See Path.
+// The in-memory readers, such as *bytes.Reader and *strings.Reader, and the
+// inputs of a known size up to 256 KiB are counted in the calling goroutine.
+// The unread part of a larger regular *os.File is counted in parallel the same
+// way as CountLinesReaderAt. In both cases, the read offset is moved to the
+// end. The other inputs, such as pipes, are read sequentially.
+//
+// Note that a single blocking Read call of the reader can not be interrupted.
+func CountLinesContext(ctx context.Context, inputReader io.Reader) (int, error) {
+	count, err := CountLinesContext64(ctx, inputReader)
+	if err != nil {
+		return partialCount(count), err
 	}
 
-	wg := new(sync.WaitGroup) //nolint:varnamelen
-	bufSize := bufio.MaxScanTokenSize
-	count := uint64(0)
-	bufReader := bufio.NewReader(inputReader)
-	lastBuf := make([]byte, bufSize)
-	numIte := 0
+	return toInt(count)
+}
 
-	for {
-		numIte++
-		buf := make([]byte, bufSize*numIte)
+// ----------------------------------------------------------------------------
+//  CountLinesContext64
+// ----------------------------------------------------------------------------
 
-		numRead, err := bufReader.Read(buf) // loading chunk into buffer
-		if err != nil {
-			if err == io.EOF {
-				break
-			}
+// CountLinesContext64 is the same as CountLinesContext but returns the number of
+// lines as uint64.
+func CountLinesContext64(ctx context.Context, inputReader io.Reader) (uint64, error) {
+	if inputReader == nil {
+		return 0, ErrNilReader
+	}
 
-			return 0, errors.Wrap(err, "failed to read from reader")
+	count, err := countReader(ctx, inputReader, Options{}.normalize())
+	if err != nil {
+		if ctx.Err() != nil {
+			return count, err
 		}
 
-		task := buf[:numRead]
-		lastBuf = task
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// countReader counts the lines of the input using the suitable method for the
+// type and the size of the input. See Path for the choices.
+func countReader(ctx context.Context, inputReader io.Reader, opts Options) (uint64, error) {
+	section, seeker, ok := sectionOf(inputReader)
+	if !ok {
+		opts.notifyPath(PathStream, -1)
+
+		return countStream(ctx, inputReader, numWorkers(), opts)
+	}
+
+	var (
+		count uint64
+		err   error
+	)
+
+	path := choosePath(inputReader, section.Size())
+	opts.notifyPath(path, section.Size())
+
+	if path == PathInline {
+		count, err = countInline(ctx, section, opts)
+	} else {
+		count, err = countReaderAt(ctx, section, section.Size(), numWorkers(), opts)
+	}
 
+	if err != nil {
+		return count, err
+	}
+
+	// Move the read offset to the end as if the input was read by io.Reader.
+	_, err = seeker.Seek(section.Size(), io.SeekCurrent)
+
+	return count, errors.Wrap(err, "failed to seek to the end of the input")
+}
+
+// ----------------------------------------------------------------------------
+//  countStream
+// ----------------------------------------------------------------------------
+
+// countStream reads the input sequentially into fixed-size buffers and lets the
+// given number of workers count the terminators in them.
+//
+// At most "2 * numWorker + 1" buffers are in use at the same time, so the memory
+// usage does not depend on the size of the input. On context cancellation, it
+// returns the number of lines counted so far along with ctx.Err().
+func countStream(ctx context.Context, inputReader io.Reader, numWorker int, opts Options) (uint64, error) {
+	var (
+		count atomic.Uint64
+		wg    sync.WaitGroup
+	)
+
+	term := opts.Terminator
+	rep := newReporter(opts)
+	tasks := make(chan task, numWorker)
+
+	for range numWorker {
 		wg.Add(1)
 
 		go func() {
-			found := bytes.Count(task, []byte{'\n'})
-
-			// add only if "found" is less than maxInt
-			if found < int(maxInt) {
-				// count++ safely
-				//
-				//nolint:gosec // oveflow is checked above
-				atomic.AddUint64(&count, uint64(found))
-			}
+			defer wg.Done()
 
-			wg.Done()
+			for task := range tasks {
+				found := term.count(*task.buf) + task.straddle
+
+				count.Add(found)
+				rep.addLines(found)
+				putBuffer(task.buf)
+			}
 		}()
 	}
 
+	trail, err := readChunks(ctx, inputReader, tasks, term, rep)
+
+	// Stop the workers and wait for them to finish the queued tasks.
+	close(tasks)
 	wg.Wait()
 
-	// Detect the file ends without a line break and count up if so.
-	lenLastBuf := len(lastBuf)
-	hasFragment := false
+	if err != nil {
+		// All the chunks read before the failure are counted at this point.
+		if readErr := (*ReadError)(nil); errors.As(err, &readErr) {
+			readErr.Lines = int64(count.Load()) //nolint:gosec // never exceeds in practice
+		}
+
+		return count.Load(), err
+	}
+
+	if opts.hasFragment(trail) {
+		count.Add(1)
+	}
+
+	rep.done(count.Load())
+
+	return count.Load(), nil
+}
+
+// task is a chunk of the input to be counted by the workers.
+type task struct {
+	buf *[]byte
+	// straddle is the correction for the terminator split between the previous
+	// chunk and this one.
+	straddle uint64
+}
+
+// readChunks reads the input into pooled buffers and sends them to the tasks
+// channel until EOF. It returns the last bytes of the input to detect the fragment.
+func readChunks(
+	ctx context.Context, inputReader io.Reader, tasks chan<- task, term Terminator, rep *reporter,
+) (*trailer, error) {
+	trail := new(trailer)
+	offset := int64(0)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		buf := getBuffer()
+
+		numRead, err := inputReader.Read(*buf) // loading chunk into buffer
+		if numRead > 0 {
+			*buf = (*buf)[:numRead]
+
+			tasks <- task{buf: buf, straddle: term.straddle(trail.tail, *buf)}
+
+			trail.push(*buf)
+			rep.addBytes(numRead)
 
-	for i := lenLastBuf; i > 0; i-- {
-		tmpChar := lastBuf[i-1]
-		if tmpChar == '\x00' {
-			continue
+			offset += int64(numRead)
+		} else {
+			putBuffer(buf)
 		}
 
-		if tmpChar == '\n' {
-			break
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return trail, nil
+			}
+
+			return nil, &ReadError{Offset: offset, Err: err}
 		}
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  Helper functions
+// ----------------------------------------------------------------------------
 
-		hasFragment = true
+// getBuffer returns a chunkSize-length buffer from the pool.
+func getBuffer() *[]byte {
+	buf, _ := bufPool.Get().(*[]byte)
+	*buf = (*buf)[:chunkSize]
+
+	return buf
+}
+
+// putBuffer puts the buffer back to the pool to be reused. Buffers of other sizes
+// than chunkSize are left to the GC.
+func putBuffer(buf *[]byte) {
+	if cap(*buf) != chunkSize {
+		return
 	}
 
-	if hasFragment {
-		atomic.AddUint64(&count, 1) // count++ safely
+	bufPool.Put(buf)
+}
+
+// lastNonNULIndex returns the index of the last non-NUL byte in the buffer. It
+// returns -1 if the buffer contains only NULs.
+func lastNonNULIndex(buf []byte) int {
+	for i := len(buf) - 1; i >= 0; i-- {
+		if buf[i] != '\x00' {
+			return i
+		}
 	}
 
-	// Check overflow on 32bit systems
+	return -1
+}
+
+// numWorkers returns the number of workers to count the lines concurrently.
+func numWorkers() int {
+	return runtime.GOMAXPROCS(0)
+}
+
+// toInt converts the count to int. It returns an error if the count overflows
+// int, such as on 32bit systems.
+func toInt(count uint64) (int, error) {
 	if count > uint64(maxInt) {
-		return 0, errors.New("number of lines exceeds the maximum value of int")
+		return 0, ErrOverflow
 	}
 
 	return int(count), nil
 }
+
+// partialCount returns the count as int. If it overflows, it returns the maximum
+// value of int instead.
+func partialCount(count uint64) int {
+	if count > uint64(maxInt) {
+		return int(maxInt)
+	}
+
+	return int(count)
+}
@@ -1,7 +1,14 @@
 package cl
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
 
 	"github.com/KEINOS/go-countline/cl/spec"
 	"github.com/pkg/errors"
@@ -18,6 +25,12 @@ func TestCountLines_golden(t *testing.T) {
 	spec.RunSpecTest(t, "CountLines", CountLines)
 }
 
+func TestCountLines64_golden(t *testing.T) {
+	t.Parallel()
+
+	spec.RunSpecTest64(t, "CountLines64", CountLines64)
+}
+
 func TestCountLines_nil_input(t *testing.T) {
 	t.Parallel()
 
@@ -46,3 +59,240 @@ func TestCountLines_io_read_fail(t *testing.T) {
 	require.Contains(t, err.Error(), "failed to read from reader")
 	require.Contains(t, err.Error(), "forced error", "the error should contain the reason of the error")
 }
+
+func TestCountLinesContext_golden(t *testing.T) {
+	t.Parallel()
+
+	spec.RunSpecTest(t, "CountLinesContext", func(r io.Reader) (int, error) {
+		return CountLinesContext(context.Background(), r)
+	})
+}
+
+func TestCountLinesContext64_golden(t *testing.T) {
+	t.Parallel()
+
+	spec.RunSpecTest64(t, "CountLinesContext64", func(r io.Reader) (uint64, error) {
+		return CountLinesContext64(context.Background(), r)
+	})
+}
+
+func TestCountLinesContext_canceled_before_start(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	numLines, err := CountLinesContext(ctx, strings.NewReader("Hello\nWorld\n"))
+
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, 0, numLines, "nothing should be counted if canceled before start")
+}
+
+func TestCountLinesContext_canceled_while_reading(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Cancel the context on the 3rd read. The context is checked before each
+	// read, so the chunk of the 3rd read is still counted.
+	reader := &CancelReader{
+		chunk:    []byte("line\n"),
+		cancelAt: 3,
+		cancel:   cancel,
+	}
+
+	numLines, err := CountLinesContext(ctx, reader)
+
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, 3, numLines, "it should return the number of lines counted before cancellation")
+}
+
+func TestCountLinesContext_deadline_exceeded(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+
+	// Endless reader that stalls on each read
+	reader := &SlowReader{delay: 10 * time.Millisecond}
+
+	_, err := CountLinesContext(ctx, reader)
+
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func Test_toInt(t *testing.T) {
+	t.Parallel()
+
+	numLines, err := toInt(10)
+
+	require.NoError(t, err)
+	require.Equal(t, 10, numLines)
+
+	numLines, err = toInt(uint64(maxInt) + 1)
+
+	require.Error(t, err)
+	require.Equal(t, 0, numLines)
+	require.Contains(t, err.Error(), "number of lines exceeds the maximum value of int")
+}
+
+func Test_partialCount(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, 10, partialCount(10))
+	require.Equal(t, int(maxInt), partialCount(uint64(maxInt)+1),
+		"overflowed count should be capped to the max value of int")
+}
+
+func Test_countStream_multiple_workers(t *testing.T) {
+	t.Parallel()
+
+	for _, numWorker := range []int{1, 2, 4, 16} {
+		spec.RunSpecTest(t, fmt.Sprintf("countStream_%d_workers", numWorker), func(r io.Reader) (int, error) {
+			count, err := countStream(context.Background(), r, numWorker, Options{}.normalize())
+
+			return int(count), err //nolint:gosec // small number of lines in spec tests
+		})
+	}
+}
+
+func Test_countStream_data_with_eof(t *testing.T) {
+	t.Parallel()
+
+	// Reader that returns the data and io.EOF at the same time.
+	reader := iotest.DataErrReader(strings.NewReader("Hello\nWorld"))
+
+	count, err := countStream(context.Background(), reader, 2, Options{}.normalize())
+
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), count)
+}
+
+func Test_countStream_trailing_nul(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		input  string
+		expect uint64
+	}{
+		{input: "\x00\x00\x00", expect: 0},
+		{input: "Hello\n\x00\x00", expect: 1},
+		{input: "Hello\x00\x00", expect: 1},
+		{input: "Hello\n" + strings.Repeat("\x00", chunkSize*2), expect: 1},
+	} {
+		// Read byte by byte to split the input into many chunks
+		reader := iotest.OneByteReader(strings.NewReader(test.input))
+
+		count, err := countStream(context.Background(), reader, 2, Options{}.normalize())
+
+		require.NoError(t, err)
+		require.Equal(t, test.expect, count, "input: %q", test.input)
+	}
+}
+
+// The memory usage must not depend on the size of the input. Peak memory should
+// be O(workers * chunkSize).
+//
+//nolint:paralleltest // do not parallelize to measure the memory allocation
+func Test_countStream_memory_ceiling(t *testing.T) {
+	if isRaceEnabled {
+		t.Skip("sync.Pool does not reuse the buffers reliably under the race detector")
+	}
+
+	const (
+		numWorker = 4
+		lenLine   = 64
+		sizeInput = 256 * 1024 * 1024 // 256 MiB
+	)
+
+	// Buffers in use at the same time plus some room for the runtime.
+	const ceiling = (2*numWorker+1)*chunkSize + 1024*1024
+
+	reader := &RepeatReader{
+		line:   []byte(spec.GetStrDummyLines(lenLine, 1)),
+		remain: sizeInput,
+	}
+
+	var before, after runtime.MemStats
+
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	count, err := countStream(context.Background(), reader, numWorker, Options{}.normalize())
+
+	runtime.ReadMemStats(&after)
+
+	require.NoError(t, err)
+	require.Equal(t, uint64(sizeInput/lenLine), count)
+
+	allocated := after.TotalAlloc - before.TotalAlloc
+
+	t.Logf("allocated: %d bytes for %d bytes of input", allocated, sizeInput)
+	require.Less(t, allocated, uint64(ceiling),
+		"allocated memory should not depend on the input size")
+}
+
+// ============================================================================
+//  Helpers
+// ============================================================================
+
+// CancelReader is an endless io.Reader that returns the chunk on each read and
+// calls the cancel function on the "cancelAt"-th read.
+type CancelReader struct {
+	cancel   context.CancelFunc
+	chunk    []byte
+	cancelAt int
+	numRead  int
+}
+
+func (r *CancelReader) Read(p []byte) (int, error) {
+	r.numRead++
+
+	if r.numRead == r.cancelAt {
+		r.cancel()
+	}
+
+	return copy(p, r.chunk), nil
+}
+
+// RepeatReader is an io.Reader that repeats the line until "remain" bytes are
+// read. It does not allocate on Read.
+type RepeatReader struct {
+	line   []byte
+	remain int64
+	offset int
+}
+
+func (r *RepeatReader) Read(p []byte) (int, error) {
+	if r.remain <= 0 {
+		return 0, io.EOF
+	}
+
+	if int64(len(p)) > r.remain {
+		p = p[:r.remain]
+	}
+
+	numRead := 0
+
+	for numRead < len(p) {
+		copied := copy(p[numRead:], r.line[r.offset:])
+		numRead += copied
+		r.offset = (r.offset + copied) % len(r.line)
+	}
+
+	r.remain -= int64(numRead)
+
+	return numRead, nil
+}
+
+// SlowReader is an endless io.Reader that sleeps for the given delay on each read.
+type SlowReader struct {
+	delay time.Duration
+}
+
+func (r *SlowReader) Read(p []byte) (int, error) {
+	time.Sleep(r.delay)
+
+	return copy(p, "line\n"), nil
+}
@@ -0,0 +1,99 @@
+package cl
+
+import (
+	"io"
+)
+
+// ----------------------------------------------------------------------------
+//  Type: Counter
+// ----------------------------------------------------------------------------
+
+// Counter is an io.Writer that counts the lines of the data written to it. It
+// keeps the state between the Write calls, so a terminator or a line split across
+// the writes is counted correctly.
+//
+// Useful to count the lines while the data flows to somewhere else, such as with
+// io.MultiWriter. The zero value is ready to use and counts the same way as
+// CountLines. Counter is not safe for concurrent use.
+type Counter struct {
+	trail trailer
+	opts  Options
+	count uint64
+}
+
+// NewCounter returns a new Counter that counts the lines with the given options.
+func NewCounter(opts Options) *Counter {
+	return &Counter{opts: opts.normalize()}
+}
+
+// Write counts the lines of p. It never returns an error.
+func (c *Counter) Write(p []byte) (int, error) {
+	term := c.opts.Terminator.orDefault()
+
+	c.count += term.count(p) + term.straddle(c.trail.tail, p)
+	c.trail.push(p)
+
+	return len(p), nil
+}
+
+// Count returns the number of lines written so far. The last line that does not
+// end with a terminator is counted as well, unless the WcCompat option is set.
+//
+// If the number exceeds the maximum value of int, it returns the maximum value.
+// Use Count64 to get the exact number.
+func (c *Counter) Count() int {
+	return partialCount(c.Count64())
+}
+
+// Count64 is the same as Count but returns the number of lines as uint64.
+func (c *Counter) Count64() uint64 {
+	count := c.count
+
+	if c.opts.normalize().hasFragment(&c.trail) {
+		count++
+	}
+
+	return count
+}
+
+// Reset resets the count to zero to be reused. The options are kept.
+func (c *Counter) Reset() {
+	c.count = 0
+	c.trail = trailer{}
+}
+
+// ----------------------------------------------------------------------------
+//  Type: CountingReader
+// ----------------------------------------------------------------------------
+
+// CountingReader is an io.Reader that counts the lines of the data read through
+// it. Use NewCountingReader to create one.
+type CountingReader struct {
+	reader  io.Reader
+	counter Counter
+}
+
+// NewCountingReader returns a new CountingReader that reads from the given reader
+// and counts the lines the same way as CountLines.
+func NewCountingReader(inputReader io.Reader) *CountingReader {
+	return &CountingReader{reader: inputReader}
+}
+
+// Read reads from the underlying reader and counts the lines of the read data.
+func (cr *CountingReader) Read(p []byte) (int, error) {
+	numRead, err := cr.reader.Read(p)
+
+	_, _ = cr.counter.Write(p[:numRead])
+
+	return numRead, err //nolint:wrapcheck // return the error as is, such as io.EOF
+}
+
+// Count returns the number of lines read so far.
+func (cr *CountingReader) Count() int {
+	return cr.counter.Count()
+}
+
+// Count64 is the same as Count but returns the number of lines as uint64.
+func (cr *CountingReader) Count64() uint64 {
+	return cr.counter.Count64()
+}
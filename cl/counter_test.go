@@ -0,0 +1,144 @@
+package cl
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/KEINOS/go-countline/cl/spec"
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+//  Tests
+// ============================================================================
+
+func TestCounter_golden(t *testing.T) {
+	t.Parallel()
+
+	spec.RunSpecTest(t, "Counter", func(r io.Reader) (int, error) {
+		counter := new(Counter)
+
+		// Write byte by byte to split the lines across the writes
+		_, err := io.Copy(counter, iotest.OneByteReader(r))
+
+		return counter.Count(), err
+	})
+}
+
+func TestCounter_Count64(t *testing.T) {
+	t.Parallel()
+
+	spec.RunSpecTest64(t, "Counter.Count64", func(r io.Reader) (uint64, error) {
+		counter := new(Counter)
+
+		_, err := io.Copy(counter, r)
+
+		return counter.Count64(), err
+	})
+}
+
+func TestCounter_terminator(t *testing.T) {
+	t.Parallel()
+
+	spec.RunSpecTestTerminator(t, "Counter", func(terminator string, r io.Reader) (int, error) {
+		counter := NewCounter(Options{Terminator: parseTerminator(t, terminator)})
+
+		_, err := io.Copy(counter, iotest.OneByteReader(r))
+
+		return counter.Count(), err
+	})
+}
+
+func TestCounter_wc_compat(t *testing.T) {
+	t.Parallel()
+
+	spec.RunSpecTestNewlines(t, "Counter", func(r io.Reader) (int, error) {
+		counter := NewCounter(Options{WcCompat: true})
+
+		_, err := io.Copy(counter, r)
+
+		return counter.Count(), err
+	})
+}
+
+func TestCounter_Reset(t *testing.T) {
+	t.Parallel()
+
+	counter := NewCounter(Options{Terminator: CRLF})
+
+	_, err := counter.Write([]byte("Hello\r\nWorld\r"))
+
+	require.NoError(t, err)
+	require.Equal(t, 2, counter.Count())
+
+	counter.Reset()
+
+	require.Equal(t, 0, counter.Count(), "count should be zero after reset")
+
+	// The CR written before reset should not form a CRLF
+	_, err = counter.Write([]byte("\nFoo"))
+
+	require.NoError(t, err)
+	require.Equal(t, 1, counter.Count(), "state before reset should not affect the count")
+	require.Equal(t, CRLF, counter.opts.Terminator, "options should be kept after reset")
+}
+
+func TestCounter_count_so_far(t *testing.T) {
+	t.Parallel()
+
+	counter := new(Counter)
+
+	for _, test := range []struct {
+		input  string
+		expect int
+	}{
+		{input: "Hello", expect: 1},
+		{input: " World", expect: 1},
+		{input: "\n", expect: 1},
+		{input: "\nFoo", expect: 3},
+	} {
+		numWritten, err := counter.Write([]byte(test.input))
+
+		require.NoError(t, err)
+		require.Equal(t, len(test.input), numWritten)
+		require.Equal(t, test.expect, counter.Count(), "after writing %q", test.input)
+	}
+}
+
+func TestCountingReader(t *testing.T) {
+	t.Parallel()
+
+	spec.RunSpecTest(t, "CountingReader", func(r io.Reader) (int, error) {
+		reader := NewCountingReader(iotest.HalfReader(r))
+
+		_, err := io.Copy(io.Discard, reader)
+
+		return reader.Count(), err
+	})
+}
+
+func TestCountingReader_Count64(t *testing.T) {
+	t.Parallel()
+
+	spec.RunSpecTest64(t, "CountingReader.Count64", func(r io.Reader) (uint64, error) {
+		reader := NewCountingReader(r)
+
+		_, err := io.Copy(io.Discard, reader)
+
+		return reader.Count64(), err
+	})
+}
+
+func TestCountingReader_io_read_fail(t *testing.T) {
+	t.Parallel()
+
+	reader := NewCountingReader(io.MultiReader(strings.NewReader("Hello\nWorld"), &DummyReader{}))
+
+	_, err := io.ReadAll(reader)
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "forced error")
+	require.Equal(t, 2, reader.Count(), "lines read before the error should be counted")
+}
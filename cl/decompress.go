@@ -0,0 +1,247 @@
+package cl
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+//  Type: Compression
+// ----------------------------------------------------------------------------
+
+// Compression is the compression format of the input.
+type Compression uint8
+
+const (
+	// Uncompressed is the plain input.
+	Uncompressed Compression = iota
+	// Gzip is the gzip format (.gz).
+	Gzip
+	// Bzip2 is the bzip2 format (.bz2).
+	Bzip2
+	// Zlib is the zlib format.
+	Zlib
+	// LZW is the format of the Unix "compress" command (.Z). It is detected but
+	// not supported by Decompress.
+	LZW
+)
+
+// Magic bytes of the compression formats.
+//
+//nolint:gochecknoglobals // byte sequences of the magic bytes
+var (
+	magicGzip  = []byte{0x1f, 0x8b}
+	magicBzip2 = []byte("BZh")
+	magicLZW   = []byte{0x1f, 0x9d}
+)
+
+// String returns the name of the compression format.
+func (c Compression) String() string {
+	switch c {
+	case Uncompressed:
+		return "uncompressed"
+	case Gzip:
+		return "gzip"
+	case Bzip2:
+		return "bzip2"
+	case Zlib:
+		return "zlib"
+	case LZW:
+		return "lzw"
+	}
+
+	return "unknown"
+}
+
+// DetectCompression returns the compression format of the data by its magic
+// bytes. The data should be the first few bytes of the input.
+//
+// Only the zlib headers of the default window size with the typical compression
+// levels are detected, since the 2-byte header is often seen in plain text too.
+// It is a guess by the magic bytes only. Decompress verifies it by decoding.
+func DetectCompression(head []byte) Compression {
+	switch {
+	case bytes.HasPrefix(head, magicGzip):
+		return Gzip
+	case bytes.HasPrefix(head, magicLZW):
+		return LZW
+	case bytes.HasPrefix(head, magicBzip2) && len(head) > 3 && '1' <= head[3] && head[3] <= '9':
+		return Bzip2
+	case len(head) > 1 && head[0] == 0x78 && bytes.IndexByte([]byte{0x01, 0x5e, 0x9c, 0xda}, head[1]) >= 0:
+		return Zlib
+	}
+
+	return Uncompressed
+}
+
+// ----------------------------------------------------------------------------
+//  Decompress
+// ----------------------------------------------------------------------------
+
+// Decompress detects the compression format of the input by the magic bytes and
+// returns the reader of the decompressed data. The input is returned as is, but
+// buffered, if it is not compressed.
+//
+// The magic bytes of bzip2 and zlib are short enough to appear in plain text,
+// such as "BZh1" and "x^". These formats are reported only after the first block
+// is decoded. If the header or the first block fails to decode, the input is
+// read as uncompressed.
+//
+// The concatenated gzip and bzip2 streams, such as "cat a.gz b.gz", are read as a
+// whole.
+//
+// The LZW format (.Z) is not supported. ErrUnsupportedFormat is returned instead
+// of counting the compressed bytes as plain text.
+func Decompress(inputReader io.Reader) (io.Reader, Compression, error) {
+	if inputReader == nil {
+		return nil, Uncompressed, ErrNilReader
+	}
+
+	const lenMagic = 4
+
+	buffered := bufio.NewReaderSize(inputReader, chunkSize)
+
+	head, err := buffered.Peek(lenMagic)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, Uncompressed, &ReadError{Err: err}
+	}
+
+	switch format := DetectCompression(head); format {
+	case Gzip:
+		// Multistream is enabled by default
+		decompressed, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, format, errors.Wrapf(err, "failed to decompress %s data", format)
+		}
+
+		return decompressed, format, nil
+	case Bzip2:
+		return probeDecoder(buffered, format, func(compressed io.Reader) (io.Reader, error) {
+			return bzip2.NewReader(compressed), nil
+		})
+	case Zlib:
+		return probeDecoder(buffered, format, func(compressed io.Reader) (io.Reader, error) {
+			return zlib.NewReader(compressed)
+		})
+	case LZW:
+		return nil, format, errors.Wrapf(ErrUnsupportedFormat, "failed to decompress %s data", format)
+	}
+
+	return buffered, Uncompressed, nil
+}
+
+// probeDecoder returns the reader of the decompressed data if the decoder decodes
+// the first chunk of the input, or the whole input if shorter. Otherwise, it
+// returns the input as is with the bytes read by the decoder put back.
+//
+// A single read is not enough since the decoder may return a few bytes of plain
+// text before finding it corrupt.
+func probeDecoder(
+	buffered io.Reader,
+	format Compression,
+	newDecoder func(compressed io.Reader) (io.Reader, error),
+) (io.Reader, Compression, error) {
+	recorder := &recordReader{reader: buffered}
+
+	decoder, err := newDecoder(recorder)
+	if err == nil {
+		first := make([]byte, chunkSize)
+		numRead := 0
+
+		for numRead < len(first) && err == nil {
+			var numChunk int
+
+			numChunk, err = decoder.Read(first[numRead:])
+			numRead += numChunk
+		}
+
+		// io.EOF is returned only if the stream, such as its checksum, is valid
+		if err == nil || errors.Is(err, io.EOF) {
+			recorder.stop()
+
+			return io.MultiReader(bytes.NewReader(first[:numRead]), decoder), format, nil
+		}
+	}
+
+	if recorder.err != nil && !errors.Is(recorder.err, io.EOF) {
+		return nil, Uncompressed, &ReadError{Err: recorder.err}
+	}
+
+	return io.MultiReader(bytes.NewReader(recorder.buf), buffered), Uncompressed, nil
+}
+
+// ----------------------------------------------------------------------------
+//  Type: recordReader
+// ----------------------------------------------------------------------------
+
+// recordReader keeps the bytes and the error read from the reader until stop is
+// called. It is used to put back the bytes read by a decoder that failed.
+type recordReader struct {
+	reader  io.Reader
+	err     error
+	buf     []byte
+	stopped bool
+}
+
+// Read implements io.Reader.
+func (r *recordReader) Read(p []byte) (int, error) {
+	numRead, err := r.reader.Read(p)
+
+	if !r.stopped {
+		r.buf = append(r.buf, p[:numRead]...)
+
+		if err != nil {
+			r.err = err
+		}
+	}
+
+	return numRead, err //nolint:wrapcheck // the error of the reader as is
+}
+
+// stop stops recording and releases the recorded bytes.
+func (r *recordReader) stop() {
+	r.stopped = true
+	r.buf = nil
+}
+
+// ----------------------------------------------------------------------------
+//  CountLinesAuto
+// ----------------------------------------------------------------------------
+
+// CountLinesAuto is the same as CountLines but counts the decompressed lines if
+// the input is compressed in gzip, bzip2 or zlib. The format is detected by the
+// magic bytes. See Decompress.
+func CountLinesAuto(inputReader io.Reader) (int, error) {
+	count, err := CountLinesAuto64(inputReader)
+	if err != nil {
+		return 0, err
+	}
+
+	return toInt(count)
+}
+
+// ----------------------------------------------------------------------------
+//  CountLinesAuto64
+// ----------------------------------------------------------------------------
+
+// CountLinesAuto64 is the same as CountLinesAuto but returns the number of lines
+// as uint64.
+func CountLinesAuto64(inputReader io.Reader) (uint64, error) {
+	decompressed, format, err := Decompress(inputReader)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := CountLines64(decompressed)
+	if err != nil && format != Uncompressed {
+		return 0, errors.Wrapf(err, "failed to count %s data", format)
+	}
+
+	return count, err
+}
@@ -0,0 +1,246 @@
+package cl
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/KEINOS/go-countline/cl/spec"
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+//  Tests
+// ============================================================================
+
+func TestCountLinesAuto(t *testing.T) {
+	t.Parallel()
+
+	plain := []byte(strings.Repeat("Hello, world!\n", 10000) + "the last line")
+	expect := 10001
+
+	for name, input := range map[string][]byte{
+		"uncompressed":       plain,
+		"gzip":               compressGzip(t, plain),
+		"gzip multi-member":  append(compressGzip(t, plain[:1000]), compressGzip(t, plain[1000:])...),
+		"zlib default":       compressZlib(t, plain, zlib.DefaultCompression),
+		"zlib best speed":    compressZlib(t, plain, zlib.BestSpeed),
+		"zlib best compress": compressZlib(t, plain, zlib.BestCompression),
+		"zlib level 2":       compressZlib(t, plain, 2),
+	} {
+		for readerName, reader := range map[string]io.Reader{
+			"whole":     bytes.NewReader(input),
+			"one byte":  iotest.OneByteReader(bytes.NewReader(input)),
+			"half read": iotest.HalfReader(bytes.NewReader(input)),
+		} {
+			actual, err := CountLinesAuto(reader)
+
+			require.NoError(t, err, "input: %s, reader: %s", name, readerName)
+			require.Equal(t, expect, actual, "input: %s, reader: %s", name, readerName)
+		}
+	}
+}
+
+func TestCountLinesAuto_fixtures(t *testing.T) {
+	t.Parallel()
+
+	// lines200.txt.bz2 is the two concatenated streams of the first 100 lines and
+	// the rest.
+	for _, name := range []string{"lines200.txt.bz2"} {
+		osFile, err := os.Open(filepath.Join("testdata", name))
+		require.NoError(t, err)
+
+		defer osFile.Close()
+
+		actual, err := CountLinesAuto64(osFile)
+
+		require.NoError(t, err, name)
+		require.Equal(t, uint64(200), actual, name)
+	}
+}
+
+func TestCountLinesAuto_golden(t *testing.T) {
+	t.Parallel()
+
+	spec.RunSpecTest(t, "CountLinesAuto", CountLinesAuto)
+}
+
+// The bytes read by the decoder to guess the format must be put back when the
+// input turns out to be plain text.
+func TestDecompress_plain_text(t *testing.T) {
+	t.Parallel()
+
+	for _, input := range []string{
+		"x^2 + y\nfoo\n",
+		"BZh1 is not bzip2\n" + strings.Repeat("Hello, world!\n", 10000),
+	} {
+		for readerName, reader := range map[string]io.Reader{
+			"whole":    strings.NewReader(input),
+			"one byte": iotest.OneByteReader(strings.NewReader(input)),
+		} {
+			decompressed, format, err := Decompress(reader)
+			require.NoError(t, err, readerName)
+			require.Equal(t, Uncompressed, format, readerName)
+
+			actual, err := io.ReadAll(decompressed)
+			require.NoError(t, err, readerName)
+			require.Equal(t, input, string(actual), readerName)
+		}
+	}
+}
+
+func TestDecompress_empty_stream(t *testing.T) {
+	t.Parallel()
+
+	decompressed, format, err := Decompress(bytes.NewReader(compressZlib(t, nil, zlib.DefaultCompression)))
+	require.NoError(t, err)
+	require.Equal(t, Zlib, format, "empty stream should be decoded as well")
+
+	actual, err := io.ReadAll(decompressed)
+	require.NoError(t, err)
+	require.Empty(t, actual)
+}
+
+func TestDecompress_lzw_unsupported(t *testing.T) {
+	t.Parallel()
+
+	decompressed, format, err := Decompress(strings.NewReader("\x1f\x9d\x90foo"))
+
+	require.ErrorIs(t, err, ErrUnsupportedFormat)
+	require.Equal(t, LZW, format)
+	require.Nil(t, decompressed)
+}
+
+func TestCountLinesAuto_short_input(t *testing.T) {
+	t.Parallel()
+
+	for input, expect := range map[string]int{
+		"":        0,
+		"a":       1,
+		"\x1f":    1,
+		"x\n":     1,
+		"xyz\n\n": 2,
+		"BZh":     1, // too short to be bzip2
+		// Plain text that looks like the headers of zlib and bzip2
+		"x\x01":                  1,
+		"x^2 + y\nfoo\n":         2,
+		"BZh9foo bar":            1,
+		"BZh1 is not bzip2\nfoo": 2,
+	} {
+		actual, err := CountLinesAuto(strings.NewReader(input))
+
+		require.NoError(t, err, "input: %q", input)
+		require.Equal(t, expect, actual, "input: %q", input)
+	}
+}
+
+func TestCountLinesAuto_errors(t *testing.T) {
+	t.Parallel()
+
+	gzipped := compressGzip(t, []byte("foo\nbar\n"))
+
+	// Broken after the first chunk is decoded
+	zlibBroken := compressZlib(t, []byte(strings.Repeat("foo\n", chunkSize)), zlib.DefaultCompression)
+	zlibBroken[len(zlibBroken)-1]++ // checksum at the end
+
+	bzip2Stream, err := os.ReadFile(filepath.Join("testdata", "lines200.txt.bz2"))
+	require.NoError(t, err)
+
+	// Concatenated streams to be broken after the first chunk is decoded
+	bzip2Data := bytes.Repeat(bzip2Stream, 100)
+
+	for name, test := range map[string]struct {
+		input  io.Reader
+		expect string
+	}{
+		"nil reader":      {input: nil, expect: "given reader is nil"},
+		"gzip bad header": {input: bytes.NewReader([]byte{0x1f, 0x8b, 0, 0}), expect: "failed to decompress gzip data"},
+		"gzip truncated":  {input: bytes.NewReader(gzipped[:len(gzipped)-4]), expect: "failed to count gzip data"},
+		"zlib checksum":   {input: bytes.NewReader(zlibBroken), expect: "failed to count zlib data"},
+		"bzip2 truncated": {input: bytes.NewReader(bzip2Data[:len(bzip2Data)-4]), expect: "failed to count bzip2 data"},
+		"read error":      {input: &FailAfterReader{reader: strings.NewReader("")}, expect: "forced error"},
+		"probe error":     {input: &FailAfterReader{reader: strings.NewReader("BZh9")}, expect: "forced error"},
+		"lzw unsupported": {input: strings.NewReader("\x1f\x9d\x90foo"), expect: "failed to decompress lzw data: unsupported compression format"},
+	} {
+		actual, err := CountLinesAuto(test.input)
+
+		require.ErrorContains(t, err, test.expect, name)
+		require.Zero(t, actual, name)
+	}
+}
+
+func TestDetectCompression(t *testing.T) {
+	t.Parallel()
+
+	for input, expect := range map[string]Compression{
+		"":                 Uncompressed,
+		"\x1f\x8b\x08\x00": Gzip,
+		"\x1f\x9d\x90":     LZW,
+		"BZh91AY":          Bzip2,
+		"BZh0":             Uncompressed,
+		"BZh":              Uncompressed,
+		"x\x9c":            Zlib,
+		"x\x01":            Zlib,
+		"x\x5e":            Zlib,
+		"x\xda":            Zlib,
+		"xyz":              Uncompressed,
+		"x":                Uncompressed,
+		"Hello":            Uncompressed,
+	} {
+		require.Equal(t, expect, DetectCompression([]byte(input)), "input: %q", input)
+	}
+}
+
+func TestCompression_String(t *testing.T) {
+	t.Parallel()
+
+	for compression, expect := range map[Compression]string{
+		Uncompressed:     "uncompressed",
+		Gzip:             "gzip",
+		Bzip2:            "bzip2",
+		Zlib:             "zlib",
+		LZW:              "lzw",
+		Compression(255): "unknown",
+	} {
+		require.Equal(t, expect, compression.String())
+	}
+}
+
+// ============================================================================
+//  Helpers
+// ============================================================================
+
+func compressGzip(t *testing.T, data []byte) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	writer := gzip.NewWriter(&buf)
+
+	_, err := writer.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	return buf.Bytes()
+}
+
+func compressZlib(t *testing.T, data []byte, level int) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	writer, err := zlib.NewWriterLevel(&buf, level)
+	require.NoError(t, err)
+
+	_, err = writer.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	return buf.Bytes()
+}
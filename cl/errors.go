@@ -0,0 +1,56 @@
+package cl
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+//  Sentinel errors
+// ----------------------------------------------------------------------------
+
+var (
+	// ErrNilReader is returned when the given reader is nil.
+	ErrNilReader = errors.New("given reader is nil")
+	// ErrOverflow is returned when the number of lines does not fit in int, such
+	// as on 32bit systems.
+	ErrOverflow = errors.New("number of lines exceeds the maximum value of int")
+	// ErrTruncated is returned by Resume when the input is shorter than the
+	// offset of the saved state.
+	ErrTruncated = errors.New("input is truncated since the saved state")
+	// ErrRotated is returned by Resume when the bytes before the offset of the
+	// saved state have changed, such as a log rotation.
+	ErrRotated = errors.New("input is replaced since the saved state")
+	// ErrUnsupportedFormat is returned by Decompress when the input is compressed
+	// in a detected but unsupported format, such as LZW (.Z).
+	ErrUnsupportedFormat = errors.New("unsupported compression format")
+)
+
+// ----------------------------------------------------------------------------
+//  Type: ReadError
+// ----------------------------------------------------------------------------
+
+// ReadError is the error returned when reading the input fails. It wraps the
+// underlying I/O error of the reader.
+//
+// Use errors.As to get the position where the read failed.
+type ReadError struct {
+	// Err is the underlying error of the reader.
+	Err error
+	// Offset is the number of bytes successfully read from the beginning of the
+	// input before the failure.
+	Offset int64
+	// Lines is the number of lines counted in the input before the Offset.
+	Lines int64
+}
+
+// Error implements the error interface.
+func (e *ReadError) Error() string {
+	return fmt.Sprintf("failed to read from reader at offset %d: %v", e.Offset, e.Err)
+}
+
+// Unwrap returns the underlying error to be used with errors.Is and errors.As.
+func (e *ReadError) Unwrap() error {
+	return e.Err
+}
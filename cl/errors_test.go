@@ -0,0 +1,149 @@
+package cl
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+//  Tests
+// ============================================================================
+
+func TestErrNilReader(t *testing.T) {
+	t.Parallel()
+
+	for name, fn := range map[string]func() error{
+		"CountLines": func() error {
+			_, err := CountLines(nil)
+
+			return err
+		},
+		"CountLinesReaderAt": func() error {
+			_, err := CountLinesReaderAt(nil, 0)
+
+			return err
+		},
+		"CountLinesWithOptions": func() error {
+			_, err := CountLinesWithOptions(nil, Options{})
+
+			return err
+		},
+		"Stats": func() error {
+			_, err := Stats(nil)
+
+			return err
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := fn()
+
+			require.ErrorIs(t, err, ErrNilReader)
+			require.EqualError(t, err, "given reader is nil", "the message should not change")
+		})
+	}
+}
+
+func TestErrOverflow(t *testing.T) {
+	t.Parallel()
+
+	_, err := toInt(uint64(maxInt) + 1)
+
+	require.ErrorIs(t, err, ErrOverflow)
+	require.EqualError(t, err, "number of lines exceeds the maximum value of int", "the message should not change")
+}
+
+func TestReadError(t *testing.T) {
+	t.Parallel()
+
+	const (
+		lenLine  = 8
+		numLines = chunkSize / lenLine * 3 // 3 chunks
+	)
+
+	input := strings.Repeat("1234567\n", numLines)
+
+	for name, fn := range map[string]func() error{
+		"stream": func() error {
+			_, err := CountLines(&FailAfterReader{reader: strings.NewReader(input)})
+
+			return err
+		},
+		"reader_at": func() error {
+			_, err := CountLinesReaderAt(&FailAfterReaderAt{data: []byte(input)}, int64(len(input))*2)
+
+			return err
+		},
+		"reader_at_sections": func() error {
+			_, err := countReaderAt(context.Background(), &FailAfterReaderAt{data: []byte(input)},
+				int64(len(input))*2, 4, Options{}.normalize())
+
+			return err
+		},
+		"stats": func() error {
+			_, err := Stats(&FailAfterReader{reader: strings.NewReader(input)})
+
+			return err
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := fn()
+
+			var readErr *ReadError
+
+			require.ErrorAs(t, err, &readErr)
+			require.ErrorIs(t, err, errForced, "it should wrap the error of the reader")
+			require.Equal(t, int64(len(input)), readErr.Offset, "offset should be the bytes read before the failure")
+			require.Equal(t, int64(numLines), readErr.Lines, "lines should be the count before the failure")
+			require.Contains(t, err.Error(), "failed to read from reader at offset 196608: forced error")
+		})
+	}
+}
+
+// ============================================================================
+//  Helpers
+// ============================================================================
+
+var errForced = errors.New("forced error")
+
+// FailAfterReader reads the underlying reader and returns errForced instead of
+// io.EOF at the end.
+type FailAfterReader struct {
+	reader io.Reader
+}
+
+func (r *FailAfterReader) Read(p []byte) (int, error) {
+	numRead, err := r.reader.Read(p)
+	if errors.Is(err, io.EOF) {
+		return numRead, errForced
+	}
+
+	return numRead, err //nolint:wrapcheck // return the error as is
+}
+
+// FailAfterReaderAt reads the data and returns errForced for reads beyond the
+// end of the data.
+type FailAfterReaderAt struct {
+	data []byte
+}
+
+func (r *FailAfterReaderAt) ReadAt(p []byte, offset int64) (int, error) {
+	if offset >= int64(len(r.data)) {
+		return 0, errForced
+	}
+
+	numRead := copy(p, r.data[offset:])
+	if numRead < len(p) {
+		return numRead, errForced
+	}
+
+	return numRead, nil
+}
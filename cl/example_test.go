@@ -1,11 +1,18 @@
 package cl_test
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"regexp"
 	"strings"
 
 	"github.com/KEINOS/go-countline/cl"
+	"github.com/KEINOS/go-countline/cl/strategy"
 )
 
 func ExampleCountLines() {
@@ -42,3 +49,218 @@ func ExampleCountLines() {
 	// "\n\nHello" --> 3
 	// "\n\nHello\n" --> 3
 }
+
+func ExampleCounter() {
+	input := strings.NewReader("Hello\nWorld\nFoo")
+
+	var (
+		output  strings.Builder // destination of the data, such as an upload
+		counter cl.Counter
+	)
+
+	// Count the lines while copying the data
+	if _, err := io.Copy(io.MultiWriter(&output, &counter), input); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(output.String() == "Hello\nWorld\nFoo")
+	fmt.Println(counter.Count())
+	// Output:
+	// true
+	// 3
+}
+
+func ExampleNewCountingReader() {
+	reader := cl.NewCountingReader(strings.NewReader("Hello\nWorld\n"))
+
+	// Count the lines while reading the data
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(len(data))
+	fmt.Println(reader.Count())
+	// Output:
+	// 12
+	// 2
+}
+
+func ExampleBuildIndex() {
+	input := strings.NewReader("line 1\nline 2\nline 3\nline 4\nline 5\n")
+
+	// Record the offset of every 2nd line while counting
+	idx, err := cl.BuildIndex(input, 2)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println("lines:", idx.Lines())
+
+	// Jump to the 4th line without scanning from the beginning
+	section, err := idx.Seek(input, 4)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	line, err := bufio.NewReader(section).ReadString('\n')
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Print(line)
+
+	// Save the index to reuse it later
+	data, err := idx.MarshalBinary()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	restored := new(cl.Index)
+	if err := restored.UnmarshalBinary(data); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println("restored lines:", restored.Lines())
+	// Output:
+	// lines: 5
+	// line 4
+	// restored lines: 5
+}
+
+func ExampleResume() {
+	logData := "line 1\nline 2\n"
+
+	// The first run counts the whole input
+	state, err := cl.Resume(cl.State{}, strings.NewReader(logData), int64(len(logData)))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println("lines:", state.Lines())
+
+	// The next run counts the appended part only
+	logData += "line 3\nline 4"
+
+	state, err = cl.Resume(state, strings.NewReader(logData), int64(len(logData)))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println("lines:", state.Lines())
+
+	// Replaced input, such as a rotated log file, is detected
+	logData = "new line 1\nnew line 2\nnew line 3\n"
+
+	_, err = cl.Resume(state, strings.NewReader(logData), int64(len(logData)))
+
+	fmt.Println(errors.Is(err, cl.ErrRotated))
+	// Output:
+	// lines: 2
+	// lines: 4
+	// true
+}
+
+func ExampleCountMatching() {
+	logData := "INFO start\nERROR foo\nWARN bar\nERROR buzz\nINFO end"
+
+	// Literal pattern is the fastest
+	count, err := cl.CountMatching(strings.NewReader(logData), cl.Literal("ERROR"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println("ERROR lines:", count)
+
+	// Regular expression and inverted match, the same as "grep -vc"
+	count, err = cl.CountMatching(strings.NewReader(logData), cl.Invert(regexp.MustCompile(`^(INFO|WARN)`)))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println("non INFO/WARN lines:", count)
+	// Output:
+	// ERROR lines: 2
+	// non INFO/WARN lines: 2
+}
+
+func ExampleCountLineKinds() {
+	csvData := "id,name\n1,foo\n2,bar\n\n  \n"
+
+	kinds, err := cl.CountLineKinds(strings.NewReader(csvData))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println("empty:", kinds.Empty)
+	fmt.Println("whitespace only:", kinds.Whitespace)
+	fmt.Println("content:", kinds.Content)
+	fmt.Println("total:", kinds.Total())
+	// Output:
+	// empty: 1
+	// whitespace only: 1
+	// content: 3
+	// total: 5
+}
+
+func ExampleCountLinesAuto() {
+	// Two gzip members concatenated, such as "cat a.gz b.gz"
+	var compressed bytes.Buffer
+
+	for _, data := range []string{"foo\nbar\n", "buzz\n"} {
+		writer := gzip.NewWriter(&compressed)
+
+		if _, err := writer.Write([]byte(data)); err != nil {
+			log.Fatal(err)
+		}
+
+		if err := writer.Close(); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	count, err := cl.CountLinesAuto(&compressed)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(count)
+	// Output: 3
+}
+
+func ExampleWithStrategy() {
+	// Count with the byte-by-byte loop instead of the built-in implementation
+	byteLoop, ok := strategy.Lookup(strategy.ByteLoop)
+	if !ok {
+		log.Fatal("strategy not registered")
+	}
+
+	count, err := cl.CountLinesWithOptions(strings.NewReader("foo\nbar\nbuzz"), cl.WithStrategy(byteLoop))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(count)
+	// Output: 3
+}
+
+func ExampleOptions_debugHook() {
+	opts := cl.Options{
+		DebugHook: func(path cl.Path, size int64) {
+			fmt.Println("path:", path, "size:", size)
+		},
+	}
+
+	// In-memory readers are counted in the calling goroutine
+	if _, err := cl.CountLinesWithOptions(strings.NewReader("foo\nbar\n"), opts); err != nil {
+		log.Fatal(err)
+	}
+
+	// Inputs of an unknown size are streamed to the workers
+	if _, err := cl.CountLinesWithOptions(bufio.NewReader(strings.NewReader("foo\nbar\n")), opts); err != nil {
+		log.Fatal(err)
+	}
+	// Output:
+	// path: inline size: 8
+	// path: stream size: -1
+}
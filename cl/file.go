@@ -0,0 +1,146 @@
+package cl
+
+import (
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+//  CountLinesFile
+// ----------------------------------------------------------------------------
+
+// CountLinesFile counts the number of lines in the file of the given path.
+//
+// On Linux, the file is memory-mapped read-only and counted in parallel without
+// copying the contents into Go buffers. If the file can not be mapped, such as
+// pipes, procfs files and other platforms, it falls back to CountLines.
+func CountLinesFile(pathFile string) (int, error) {
+	count, err := CountLinesFile64(pathFile)
+	if err != nil {
+		return 0, err
+	}
+
+	return toInt(count)
+}
+
+// ----------------------------------------------------------------------------
+//  CountLinesFile64
+// ----------------------------------------------------------------------------
+
+// CountLinesFile64 is the same as CountLinesFile but returns the number of lines
+// as uint64.
+//
+// Files larger than the address space, such as over 2 GiB on 32bit systems, are
+// not mapped and counted via CountLines64 instead.
+func CountLinesFile64(pathFile string) (uint64, error) {
+	osFile, err := os.Open(filepath.Clean(pathFile))
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to open file")
+	}
+
+	defer osFile.Close()
+
+	data, unmap, err := mmapFile(osFile)
+	if err != nil {
+		// Fall back to the reader for files that can not be mapped.
+		return CountLines64(osFile)
+	}
+
+	defer unmap()
+
+	count, err := countMapped(data, numWorkers())
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// ----------------------------------------------------------------------------
+//  countMapped
+// ----------------------------------------------------------------------------
+
+// countMapped splits the memory-mapped data into "numSection" sections and counts
+// the line breaks of each section in parallel.
+//
+// If the underlying file is truncated while counting, accessing the mapped region
+// raises a fault (SIGBUS) which is returned as an error instead of crashing.
+func countMapped(data []byte, numSection int) (uint64, error) {
+	if maxSection := (len(data) + chunkSize - 1) / chunkSize; numSection > maxSection {
+		numSection = max(maxSection, 1)
+	}
+
+	var wg sync.WaitGroup
+
+	counts := make([]uint64, numSection)
+	errs := make([]error, numSection+1)
+	lenSection := len(data) / numSection
+
+	for index := range numSection {
+		begin := index * lenSection
+		end := begin + lenSection
+
+		if index == numSection-1 {
+			end = len(data) // the last section takes the remainder
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			errs[index] = catchFault(func() {
+				counts[index] = LF.count(data[begin:end])
+			})
+		}()
+	}
+
+	// Detect the file ends without a line break while the sections are counted.
+	var trail trailer
+
+	errs[numSection] = catchFault(func() {
+		trail.push(data)
+	})
+
+	wg.Wait()
+
+	count := uint64(0)
+
+	for index, err := range errs {
+		if err != nil {
+			return 0, err
+		}
+
+		if index < numSection {
+			count += counts[index]
+		}
+	}
+
+	if trail.hasFragment(LF) {
+		count++
+	}
+
+	return count, nil
+}
+
+// catchFault runs the function and returns the memory fault, such as SIGBUS on
+// the mapped region, as an error.
+//
+//nolint:nonamedreturns // named return is required to return the recovered error
+func catchFault(fn func()) (err error) {
+	defer debug.SetPanicOnFault(debug.SetPanicOnFault(true))
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("failed to read the mapped file: %v", r)
+		}
+	}()
+
+	fn()
+
+	return nil
+}
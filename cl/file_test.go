@@ -0,0 +1,96 @@
+package cl
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/KEINOS/go-countline/cl/spec"
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+//  Tests
+// ============================================================================
+
+func TestCountLinesFile_golden(t *testing.T) {
+	t.Parallel()
+
+	spec.RunSpecTest(t, "CountLinesFile", func(r io.Reader) (int, error) {
+		return CountLinesFile(writeTempFile(t, r))
+	})
+}
+
+func TestCountLinesFile64_golden(t *testing.T) {
+	t.Parallel()
+
+	spec.RunSpecTest64(t, "CountLinesFile64", func(r io.Reader) (uint64, error) {
+		return CountLinesFile64(writeTempFile(t, r))
+	})
+}
+
+func TestCountLinesFile_empty_file(t *testing.T) {
+	t.Parallel()
+
+	pathFile := filepath.Join(t.TempDir(), "empty.txt")
+	require.NoError(t, os.WriteFile(pathFile, nil, 0o600))
+
+	numLines, err := CountLinesFile(pathFile)
+
+	require.NoError(t, err)
+	require.Equal(t, 0, numLines)
+}
+
+func TestCountLinesFile_missing_file(t *testing.T) {
+	t.Parallel()
+
+	numLines, err := CountLinesFile(filepath.Join(t.TempDir(), "missing.txt"))
+
+	require.Error(t, err)
+	require.Equal(t, 0, numLines, "returned number of lines should be 0 on error")
+	require.Contains(t, err.Error(), "failed to open file")
+}
+
+func TestCountLinesFile_directory(t *testing.T) {
+	t.Parallel()
+
+	numLines, err := CountLinesFile(t.TempDir())
+
+	require.Error(t, err, "directories should fail to read")
+	require.Equal(t, 0, numLines, "returned number of lines should be 0 on error")
+}
+
+func Test_countMapped_multiple_sections(t *testing.T) {
+	t.Parallel()
+
+	for _, numSection := range []int{1, 2, 3, 8} {
+		spec.RunSpecTest(t, fmt.Sprintf("countMapped_%d_sections", numSection), func(r io.Reader) (int, error) {
+			data, err := io.ReadAll(r)
+			require.NoError(t, err)
+
+			count, err := countMapped(data, numSection)
+
+			return int(count), err //nolint:gosec // small number of lines in spec tests
+		})
+	}
+}
+
+// ============================================================================
+//  Helpers
+// ============================================================================
+
+// writeTempFile writes the contents of the reader to a temporary file and returns
+// its path.
+func writeTempFile(t *testing.T, r io.Reader) string {
+	t.Helper()
+
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	pathFile := filepath.Join(t.TempDir(), "data.txt")
+	require.NoError(t, os.WriteFile(pathFile, data, 0o600))
+
+	return pathFile
+}
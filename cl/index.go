@@ -0,0 +1,296 @@
+package cl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+// indexMagic is the header of the binary encoding of Index.
+const indexMagic = "CLIX"
+
+// indexVersion is the version of the binary encoding of Index.
+const indexVersion = 1
+
+// ----------------------------------------------------------------------------
+//  BuildIndex
+// ----------------------------------------------------------------------------
+
+// BuildIndex counts the lines of the input the same way as CountLines and records
+// the byte offset of every "every"th line in the same pass.
+//
+// The index is sparse. It holds about "lines / every" offsets, so the memory usage
+// and the size of the encoded index are adjustable by "every". Use Index.Seek to
+// jump to a line of the same input.
+func BuildIndex(inputReader io.Reader, every int) (*Index, error) {
+	if inputReader == nil {
+		return nil, ErrNilReader
+	}
+
+	if every <= 0 {
+		return nil, errors.Errorf("invalid interval: %d", every)
+	}
+
+	idx := &Index{
+		every:   every,
+		offsets: []int64{0}, // the first line always begins at 0
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	trail := new(trailer)
+	count := uint64(0)
+	next := uint64(every) // the line break number that begins the next indexed line
+
+	for {
+		numRead, err := inputReader.Read(*buf)
+		chunk := (*buf)[:numRead]
+
+		found := LF.count(chunk)
+
+		// Walk through the line breaks only if the chunk contains the next one to
+		// be indexed. Otherwise count them in bulk.
+		for pos := 0; count+found >= next; {
+			end := bytes.IndexByte(chunk[pos:], '\n')
+			pos += end + 1
+			found--
+			count++
+
+			if count == next {
+				idx.offsets = append(idx.offsets, idx.size+int64(pos))
+				next += uint64(every)
+			}
+		}
+
+		count += found
+		idx.size += int64(numRead)
+
+		trail.push(chunk)
+
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return nil, &ReadError{Offset: idx.size, Lines: int64(count), Err: err} //nolint:gosec // never exceeds
+		}
+	}
+
+	if trail.hasFragment(LF) {
+		count++
+	}
+
+	idx.lines = int64(count) //nolint:gosec // never exceeds the max value of int64 in practice
+
+	// Drop the offset of the line after the last line break at the end.
+	idx.offsets = idx.offsets[:(max(idx.lines, 1)-1)/int64(every)+1]
+
+	return idx, nil
+}
+
+// ----------------------------------------------------------------------------
+//  Type: Index
+// ----------------------------------------------------------------------------
+
+// Index is a sparse index of the byte offsets of the lines. Use BuildIndex to
+// create one.
+//
+// The index can be saved and restored with MarshalBinary and UnmarshalBinary.
+type Index struct {
+	// offsets[i] is the byte offset of the line "i * every + 1".
+	offsets []int64
+	every   int
+	lines   int64
+	size    int64
+}
+
+// Every returns the interval of the indexed lines.
+func (idx *Index) Every() int {
+	return idx.every
+}
+
+// Lines returns the number of lines of the indexed input.
+func (idx *Index) Lines() int64 {
+	return idx.lines
+}
+
+// Size returns the size in bytes of the indexed input.
+func (idx *Index) Size() int64 {
+	return idx.size
+}
+
+// Seek returns a reader of the input positioned at the beginning of the given
+// line. The line number begins at 1.
+//
+// The reader must be the same input that the index was built from. It reads at
+// most "every - 1" lines from the nearest indexed line to find the position.
+func (idx *Index) Seek(inputReader io.ReaderAt, line int64) (*io.SectionReader, error) {
+	if inputReader == nil {
+		return nil, ErrNilReader
+	}
+
+	if line < 1 || line > idx.lines {
+		return nil, errors.Errorf("line %d is out of range [1, %d]", line, idx.lines)
+	}
+
+	nearest := (line - 1) / int64(idx.every)
+	offset := idx.offsets[nearest]
+
+	offset, err := skipLines(inputReader, offset, idx.size, line-1-nearest*int64(idx.every))
+	if err != nil {
+		return nil, err
+	}
+
+	return io.NewSectionReader(inputReader, offset, idx.size-offset), nil
+}
+
+// skipLines returns the offset after "numSkip" line breaks from the given offset.
+func skipLines(inputReader io.ReaderAt, offset, size, numSkip int64) (int64, error) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	for numSkip > 0 {
+		if offset >= size {
+			return 0, errors.New("index does not match the input")
+		}
+
+		chunk := (*buf)[:min(int64(len(*buf)), size-offset)]
+
+		numRead, err := inputReader.ReadAt(chunk, offset)
+		chunk = chunk[:numRead]
+
+		for numSkip > 0 {
+			pos := bytes.IndexByte(chunk, '\n')
+			if pos < 0 {
+				break
+			}
+
+			chunk = chunk[pos+1:]
+			offset += int64(pos) + 1
+			numSkip--
+		}
+
+		if numSkip == 0 {
+			break
+		}
+
+		offset += int64(len(chunk))
+
+		if err != nil && !errors.Is(err, io.EOF) {
+			return 0, &ReadError{Offset: offset, Err: err}
+		}
+
+		if numRead == 0 {
+			return 0, errors.New("index does not match the input")
+		}
+	}
+
+	return offset, nil
+}
+
+// ----------------------------------------------------------------------------
+//  Binary encoding
+// ----------------------------------------------------------------------------
+
+// MarshalBinary encodes the index into a compact binary form. It implements the
+// encoding.BinaryMarshaler interface.
+//
+// The offsets are delta-encoded as varints, so each offset takes only a few bytes
+// depending on the length of the lines between them.
+func (idx *Index) MarshalBinary() ([]byte, error) {
+	data := append([]byte(indexMagic), indexVersion)
+
+	for _, value := range []int64{int64(idx.every), idx.lines, idx.size, int64(len(idx.offsets))} {
+		data = binary.AppendUvarint(data, uint64(value)) //nolint:gosec // the values are never negative
+	}
+
+	prev := int64(0)
+
+	for _, offset := range idx.offsets {
+		data = binary.AppendUvarint(data, uint64(offset-prev)) //nolint:gosec // offsets are in ascending order
+		prev = offset
+	}
+
+	return data, nil
+}
+
+// UnmarshalBinary decodes the index encoded by MarshalBinary. It implements the
+// encoding.BinaryUnmarshaler interface.
+func (idx *Index) UnmarshalBinary(data []byte) error {
+	if !bytes.HasPrefix(data, []byte(indexMagic)) {
+		return errors.New("invalid index: unknown format")
+	}
+
+	data = data[len(indexMagic):]
+
+	if len(data) == 0 || data[0] != indexVersion {
+		return errors.New("invalid index: unsupported version")
+	}
+
+	dec := uvarintDecoder{data: data[1:]}
+
+	every, lines, size, numOffsets := dec.next(), dec.next(), dec.next(), dec.next()
+
+	// Each offset takes at least one byte. Check it before allocating.
+	if dec.err != nil || every == 0 || every > uint64(maxInt) ||
+		lines > math.MaxInt64 || size > math.MaxInt64 ||
+		numOffsets != (max(lines, 1)-1)/every+1 || numOffsets > uint64(len(dec.data)) {
+		return errors.New("invalid index: broken header")
+	}
+
+	offsets := make([]int64, numOffsets)
+	prev := uint64(0)
+
+	for i := range offsets {
+		// Check each step since the sum of crafted deltas may wrap around
+		delta := dec.next()
+		if delta > size-prev {
+			return errors.New("invalid index: broken offsets")
+		}
+
+		prev += delta
+		offsets[i] = int64(prev) //nolint:gosec // prev <= size <= math.MaxInt64
+	}
+
+	if dec.err != nil || len(dec.data) != 0 {
+		return errors.New("invalid index: broken offsets")
+	}
+
+	//nolint:gosec // the values are checked above
+	*idx = Index{
+		offsets: offsets,
+		every:   int(every),
+		lines:   int64(lines),
+		size:    int64(size),
+	}
+
+	return nil
+}
+
+// uvarintDecoder decodes the sequence of uvarints. Once it fails, it keeps the
+// error and returns 0.
+type uvarintDecoder struct {
+	err  error
+	data []byte
+}
+
+func (d *uvarintDecoder) next() uint64 {
+	if d.err != nil {
+		return 0
+	}
+
+	value, numRead := binary.Uvarint(d.data)
+	if numRead <= 0 {
+		d.err = errors.New("malformed varint")
+
+		return 0
+	}
+
+	d.data = d.data[numRead:]
+
+	return value
+}
@@ -0,0 +1,191 @@
+package cl
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/KEINOS/go-countline/cl/spec"
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+//  Tests
+// ============================================================================
+
+func TestBuildIndex_golden(t *testing.T) {
+	t.Parallel()
+
+	spec.RunSpecTest64(t, "BuildIndex", func(r io.Reader) (uint64, error) {
+		idx, err := BuildIndex(r, 1)
+		if err != nil {
+			return 0, err
+		}
+
+		return uint64(idx.Lines()), nil //nolint:gosec // never negative
+	})
+}
+
+func TestIndex_Seek(t *testing.T) {
+	t.Parallel()
+
+	for _, input := range []string{
+		"Hello",
+		"Hello\n",
+		"\n\n\n",
+		"foo\n\nbar\nbuzz\n\nqux",
+		"foo\n\nbar\nbuzz\n\nqux\n",
+		"1\n22\n333\n4444\n55555\n666666\n7777777\n88888888\n",
+	} {
+		expect := strings.SplitAfter(input, "\n")
+		if expect[len(expect)-1] == "" {
+			expect = expect[:len(expect)-1]
+		}
+
+		for every := 1; every <= len(expect)+1; every++ {
+			idx, err := BuildIndex(strings.NewReader(input), every)
+
+			require.NoError(t, err)
+			require.Equal(t, int64(len(expect)), idx.Lines(), "input: %q", input)
+			require.Equal(t, int64(len(input)), idx.Size())
+			require.Equal(t, every, idx.Every())
+
+			for line := range expect {
+				section, err := idx.Seek(strings.NewReader(input), int64(line)+1)
+				require.NoError(t, err)
+
+				actual, err := bufio.NewReader(section).ReadString('\n')
+				if err != nil {
+					require.ErrorIs(t, err, io.EOF)
+				}
+
+				require.Equal(t, expect[line], actual, "input: %q, every: %d, line: %d", input, every, line+1)
+			}
+		}
+	}
+}
+
+func TestIndex_Seek_large(t *testing.T) {
+	t.Parallel()
+
+	const numLines = chunkSize / 10 * 5 // 5 chunks
+
+	input := spec.GetStrDummyLines(10, numLines)
+
+	idx, err := BuildIndex(strings.NewReader(input), 1000)
+
+	require.NoError(t, err)
+	require.Equal(t, int64(numLines), idx.Lines())
+	require.Len(t, idx.offsets, numLines/1000+1, "it should hold every 1000th line only")
+
+	for _, line := range []int64{1, 999, 1000, 1001, 12345, numLines} {
+		section, err := idx.Seek(strings.NewReader(input), line)
+		require.NoError(t, err)
+
+		require.Equal(t, int64(len(input))-(line-1)*10, section.Size(),
+			"line %d should begin at the offset %d", line, (line-1)*10)
+	}
+}
+
+func TestBuildIndex_errors(t *testing.T) {
+	t.Parallel()
+
+	idx, err := BuildIndex(nil, 1)
+
+	require.ErrorIs(t, err, ErrNilReader)
+	require.Nil(t, idx)
+
+	idx, err = BuildIndex(strings.NewReader("Hello"), 0)
+
+	require.ErrorContains(t, err, "invalid interval: 0")
+	require.Nil(t, idx)
+
+	idx, err = BuildIndex(&FailAfterReader{reader: strings.NewReader("foo\nbar\n")}, 1)
+
+	var readErr *ReadError
+
+	require.ErrorAs(t, err, &readErr)
+	require.Equal(t, int64(8), readErr.Offset)
+	require.Equal(t, int64(2), readErr.Lines)
+	require.Nil(t, idx)
+}
+
+func TestIndex_Seek_errors(t *testing.T) {
+	t.Parallel()
+
+	input := "foo\nbar\nbuzz\n"
+
+	idx, err := BuildIndex(strings.NewReader(input), 2)
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		reader io.ReaderAt
+		expect string
+		line   int64
+	}{
+		{reader: nil, line: 1, expect: "given reader is nil"},
+		{reader: strings.NewReader(input), line: 0, expect: "line 0 is out of range [1, 3]"},
+		{reader: strings.NewReader(input), line: 4, expect: "line 4 is out of range [1, 3]"},
+		{reader: strings.NewReader("foo"), line: 2, expect: "index does not match the input"},
+		{reader: strings.NewReader("foo bar buzz "), line: 2, expect: "index does not match the input"},
+		{reader: &DummyReaderAt{}, line: 2, expect: "failed to read from reader at offset 0: forced error"},
+	} {
+		section, err := idx.Seek(test.reader, test.line)
+
+		require.ErrorContains(t, err, test.expect)
+		require.Nil(t, section)
+	}
+}
+
+func TestIndex_MarshalBinary(t *testing.T) {
+	t.Parallel()
+
+	input := spec.GetStrDummyLines(10, 10000)
+
+	idx, err := BuildIndex(strings.NewReader(input), 100)
+	require.NoError(t, err)
+
+	data, err := idx.MarshalBinary()
+	require.NoError(t, err)
+
+	require.Less(t, len(data), len(idx.offsets)*2+32, "the offsets should be delta-encoded")
+
+	restored := new(Index)
+
+	require.NoError(t, restored.UnmarshalBinary(data))
+	require.Equal(t, idx, restored, "the index should be restored as is")
+}
+
+func TestIndex_UnmarshalBinary_invalid(t *testing.T) {
+	t.Parallel()
+
+	idx, err := BuildIndex(strings.NewReader("foo\nbar\nbuzz"), 2)
+	require.NoError(t, err)
+
+	data, err := idx.MarshalBinary()
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		expect string
+		data   []byte
+	}{
+		{expect: "unknown format", data: []byte("FOO")},
+		{expect: "unsupported version", data: []byte(indexMagic)},
+		{expect: "unsupported version", data: append([]byte(indexMagic), indexVersion+1)},
+		{expect: "broken header", data: data[:len(indexMagic)+2]},
+		{expect: "broken header", data: append([]byte(indexMagic), indexVersion, 0, 0, 0, 1, 0)},         // every is 0
+		{expect: "broken header", data: append([]byte(indexMagic), indexVersion, 2, 3, 12, 5, 0)},        // number of offsets
+		{expect: "broken offsets", data: append([]byte(indexMagic), indexVersion, 2, 3, 12, 2, 0, 0x80)}, // malformed
+		{expect: "broken offsets", data: append(data[:len(data):len(data)], 0)},
+		{expect: "broken offsets", data: append([]byte(indexMagic), indexVersion, 2, 3, 12, 2, 0, 13)}, // exceeds size
+		{expect: "broken offsets", data: binary.AppendUvarint(binary.AppendUvarint( // wraps around to -1 then 1
+			append([]byte(indexMagic), indexVersion, 2, 3, 12, 2), math.MaxUint64), 2)},
+	} {
+		err := new(Index).UnmarshalBinary(test.data)
+
+		require.ErrorContains(t, err, "invalid index: "+test.expect, "data: %v", test.data)
+	}
+}
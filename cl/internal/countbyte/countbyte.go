@@ -0,0 +1,120 @@
+/*
+Package countbyte is the kernel to count the occurrences of a few target bytes
+in a buffer in a single pass, such as LF and CR at once.
+
+Unlike bytes.Count, it counts several target bytes at once and reports the
+position of the last one found, which is the end of the last complete line.
+
+A single target is counted by bytes.Count and bytes.LastIndexByte, which are
+already vectorized by the standard library. For more targets, the pure-Go version
+compares 8 bytes per step as a single uint64 (SWAR). On amd64 with AVX2, up to 4
+target bytes are compared 64 bytes per step in assembly. The implementation is
+selected at runtime. Build with the "purego" tag to use the pure-Go version only.
+*/
+package countbyte
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/bits"
+	"slices"
+)
+
+// maxVectorTargets is the maximum number of the target bytes counted by the
+// vectorized version. More targets are counted by the SWAR version.
+const maxVectorTargets = 4
+
+// blockSize is the number of bytes compared per step by the vectorized version.
+const blockSize = 32
+
+// SWAR constants to compare the 8 bytes of a uint64 at once.
+const (
+	swarOnes = 0x0101010101010101 // multiplied by a byte to fill a word with it
+	swarLow7 = 0x7f7f7f7f7f7f7f7f // lower 7 bits of each byte
+	swarSize = 8                  // bytes per word
+)
+
+// ----------------------------------------------------------------------------
+//  Count
+// ----------------------------------------------------------------------------
+
+// Count returns the number of the bytes in buf equal to any of the targets, and
+// the index of the last one of them. The index is -1 if none is found.
+func Count(buf []byte, targets ...byte) (int, int) {
+	switch {
+	case len(targets) == 0:
+		return 0, -1
+	case len(targets) == 1:
+		return bytes.Count(buf, targets), bytes.LastIndexByte(buf, targets[0])
+	case useAVX2 && len(targets) <= maxVectorTargets && len(buf) >= blockSize:
+		return countBlocks(buf, targets)
+	}
+
+	return countSWAR(buf, targets)
+}
+
+// countBlocks counts the whole blocks of the buffer with the vectorized version
+// and the rest with the SWAR version.
+func countBlocks(buf []byte, targets []byte) (int, int) {
+	// Fill the unused slots with a duplicate, which never adds a match.
+	var set [maxVectorTargets]byte
+
+	for index := range set {
+		set[index] = targets[min(index, len(targets)-1)]
+	}
+
+	lenBlocks := len(buf) &^ (blockSize - 1)
+
+	count, last := countAVX2(buf[:lenBlocks], &set, len(targets) <= 2)
+
+	countRest, lastRest := countSWAR(buf[lenBlocks:], targets)
+	if lastRest >= 0 {
+		last = lenBlocks + lastRest
+	}
+
+	return count + countRest, last
+}
+
+// ----------------------------------------------------------------------------
+//  countSWAR
+// ----------------------------------------------------------------------------
+
+// countSWAR is the pure-Go version of Count. It compares 8 bytes per step as a
+// uint64.
+func countSWAR(buf []byte, targets []byte) (int, int) {
+	count, last := 0, -1
+	index := 0
+
+	for ; index+swarSize <= len(buf); index += swarSize {
+		word := binary.LittleEndian.Uint64(buf[index:])
+		found := uint64(0)
+
+		for _, target := range targets {
+			found |= zeroBytes(word ^ (swarOnes * uint64(target)))
+		}
+
+		if found != 0 {
+			count += bits.OnesCount64(found)
+			// The highest bit is of the last byte in little endian
+			last = index + (bits.Len64(found)-1)/swarSize
+		}
+	}
+
+	for ; index < len(buf); index++ {
+		if slices.Contains(targets, buf[index]) {
+			count++
+			last = index
+		}
+	}
+
+	return count, last
+}
+
+// zeroBytes returns the word with the highest bit set in each byte of the given
+// word that is zero, and the other bits cleared.
+//
+// Adding 0x7f to the lower 7 bits of a byte sets its highest bit unless they are
+// all zero, without carrying over to the next byte.
+func zeroBytes(word uint64) uint64 {
+	return ^(((word & swarLow7) + swarLow7) | word | swarLow7)
+}
@@ -0,0 +1,18 @@
+//go:build amd64 && !purego
+
+package countbyte
+
+import "golang.org/x/sys/cpu"
+
+// useAVX2 is true if the CPU supports the instructions used by countAVX2.
+var useAVX2 = cpu.X86.HasAVX2 && cpu.X86.HasPOPCNT
+
+// countAVX2 returns the number of the bytes in buf equal to any of the 4 targets
+// and the index of the last one of them, or -1 if none. The length of buf must be
+// a multiple of blockSize. If pair is true, only the first 2 targets are compared
+// since the rest must be duplicates of them.
+//
+// Implemented in countbyte_amd64.s.
+//
+//go:noescape
+func countAVX2(buf []byte, targets *[maxVectorTargets]byte, pair bool) (int, int)
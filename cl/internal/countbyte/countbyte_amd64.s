@@ -0,0 +1,126 @@
+//go:build amd64 && !purego
+
+#include "textflag.h"
+
+// func countAVX2(buf []byte, targets *[4]byte, pair bool) (int, int)
+TEXT ·countAVX2(SB), NOSPLIT, $0-56
+	MOVQ buf_base+0(FP), SI
+	MOVQ buf_len+8(FP), CX
+	MOVQ targets+24(FP), DI
+
+	// Fill a register with each target byte
+	VPBROADCASTB 0(DI), Y1
+	VPBROADCASTB 1(DI), Y2
+	VPBROADCASTB 2(DI), Y3
+	VPBROADCASTB 3(DI), Y4
+
+	XORQ AX, AX  // count
+	MOVQ $-1, DX // index of the last target byte
+	XORQ BX, BX  // offset of the block
+
+	// Length of the part counted 64 bytes per step
+	MOVQ CX, R11
+	ANDQ $-64, R11
+
+	// Only the first 2 targets are compared if the rest are their duplicates
+	MOVBLZX pair+32(FP), R12
+	TESTQ   R12, R12
+	JNZ     pair64
+
+loop64:
+	CMPQ BX, R11
+	JAE  loop32
+
+	// Set 0xff to the bytes equal to any of the targets, 32 bytes each
+	VMOVDQU  (SI)(BX*1), Y0
+	VMOVDQU  32(SI)(BX*1), Y9
+	VPCMPEQB Y0, Y1, Y5
+	VPCMPEQB Y0, Y2, Y6
+	VPCMPEQB Y0, Y3, Y7
+	VPCMPEQB Y0, Y4, Y8
+	VPOR     Y5, Y6, Y5
+	VPOR     Y7, Y8, Y7
+	VPOR     Y5, Y7, Y5
+	VPCMPEQB Y9, Y1, Y10
+	VPCMPEQB Y9, Y2, Y11
+	VPCMPEQB Y9, Y3, Y12
+	VPCMPEQB Y9, Y4, Y13
+	VPOR     Y10, Y11, Y10
+	VPOR     Y12, Y13, Y12
+	VPOR     Y10, Y12, Y10
+
+	// 64-bit mask of the matched bytes
+	VPMOVMSKB Y5, R8
+	VPMOVMSKB Y10, R9
+	SHLQ      $32, R9
+	ORQ       R9, R8
+
+	POPCNTQ R8, R9
+	ADDQ    R9, AX
+
+	// The highest bit of the mask is of the last byte found. BSR sets ZF if the
+	// mask is zero, then the last index is kept.
+	BSRQ    R8, R9
+	LEAQ    (BX)(R9*1), R10
+	CMOVQNE R10, DX
+
+	ADDQ $64, BX
+	JMP  loop64
+
+loop32:
+	CMPQ BX, CX
+	JAE  done
+
+	VMOVDQU   (SI)(BX*1), Y0
+	VPCMPEQB  Y0, Y1, Y5
+	VPCMPEQB  Y0, Y2, Y6
+	VPCMPEQB  Y0, Y3, Y7
+	VPCMPEQB  Y0, Y4, Y8
+	VPOR      Y5, Y6, Y5
+	VPOR      Y7, Y8, Y7
+	VPOR      Y5, Y7, Y5
+	VPMOVMSKB Y5, R8
+
+	POPCNTQ R8, R9
+	ADDQ    R9, AX
+
+	BSRQ    R8, R9
+	LEAQ    (BX)(R9*1), R10
+	CMOVQNE R10, DX
+
+	ADDQ $32, BX
+	JMP  loop32
+
+pair64:
+	CMPQ BX, R11
+	JAE  loop32
+
+	VMOVDQU  (SI)(BX*1), Y0
+	VMOVDQU  32(SI)(BX*1), Y9
+	VPCMPEQB Y0, Y1, Y5
+	VPCMPEQB Y0, Y2, Y6
+	VPOR     Y5, Y6, Y5
+	VPCMPEQB Y9, Y1, Y10
+	VPCMPEQB Y9, Y2, Y11
+	VPOR     Y10, Y11, Y10
+
+	VPMOVMSKB Y5, R8
+	VPMOVMSKB Y10, R9
+	SHLQ      $32, R9
+	ORQ       R9, R8
+
+	POPCNTQ R8, R9
+	ADDQ    R9, AX
+
+	BSRQ    R8, R9
+	LEAQ    (BX)(R9*1), R10
+	CMOVQNE R10, DX
+
+	ADDQ $64, BX
+	JMP  pair64
+
+done:
+	VZEROUPPER
+	MOVQ AX, ret+40(FP)
+	MOVQ DX, ret1+48(FP)
+	RET
@@ -0,0 +1,16 @@
+//go:build !amd64 || purego
+
+package countbyte
+
+// useAVX2 is always false since the vectorized version is only for amd64.
+var useAVX2 = false
+
+// countAVX2 is never called on this platform. It is the same as the SWAR version
+// to keep countBlocks portable.
+func countAVX2(buf []byte, targets *[maxVectorTargets]byte, pair bool) (int, int) {
+	if pair {
+		return countSWAR(buf, targets[:2])
+	}
+
+	return countSWAR(buf, targets[:])
+}
@@ -0,0 +1,168 @@
+package countbyte
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+//  Tests
+// ============================================================================
+
+func TestCount(t *testing.T) {
+	t.Parallel()
+
+	long := strings.Repeat("0123456789abcde\n", 10) + "\r\nend" // over some blocks
+
+	for _, test := range []struct {
+		input       string
+		targets     []byte
+		expectCount int
+		expectLast  int
+	}{
+		{input: "", targets: []byte("\n"), expectCount: 0, expectLast: -1},
+		{input: "foo", targets: nil, expectCount: 0, expectLast: -1},
+		{input: "foo", targets: []byte("\n"), expectCount: 0, expectLast: -1},
+		{input: "\n", targets: []byte("\n"), expectCount: 1, expectLast: 0},
+		{input: "foo\nbar\nbuzz", targets: []byte("\n"), expectCount: 2, expectLast: 7},
+		{input: "foo\r\nbar\rbuzz", targets: []byte("\n\r"), expectCount: 3, expectLast: 8},
+		{input: long, targets: []byte("\n"), expectCount: 11, expectLast: len(long) - 4},
+		{input: long, targets: []byte("\r"), expectCount: 1, expectLast: len(long) - 5},
+		{input: long, targets: []byte("\n\r0"), expectCount: 22, expectLast: len(long) - 4},
+		{input: long, targets: []byte("\n\r0ab"), expectCount: 42, expectLast: len(long) - 4},
+		{input: "\x00\x80\xff", targets: []byte{0x00, 0x80, 0xff}, expectCount: 3, expectLast: 2},
+	} {
+		count, last := Count([]byte(test.input), test.targets...)
+
+		require.Equal(t, test.expectCount, count, "input: %q, targets: %q", test.input, test.targets)
+		require.Equal(t, test.expectLast, last, "input: %q, targets: %q", test.input, test.targets)
+	}
+}
+
+// All the implementations must return the same as the naive loop regardless of
+// the alignment, the length and the position of the targets.
+func TestCount_all_positions(t *testing.T) {
+	t.Parallel()
+
+	for length := range blockSize*3 + 1 {
+		for pos := range length {
+			buf := bytes.Repeat([]byte{'a'}, length)
+			buf[pos] = '\n'
+
+			for offset := range min(length, swarSize) {
+				requireSameAsNaive(t, buf[offset:], []byte("\n"))
+			}
+		}
+	}
+}
+
+func TestZeroBytes(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, uint64(0x8080808080808080), zeroBytes(0))
+	require.Equal(t, uint64(0), zeroBytes(0x0101010101010101))
+	require.Equal(t, uint64(0), zeroBytes(0xffffffffffffffff))
+	require.Equal(t, uint64(0x0000800000000080), zeroBytes(0x0102000304050600),
+		"only the zero bytes should be marked without the false positives of borrowing")
+}
+
+func FuzzCount(f *testing.F) {
+	f.Add([]byte("foo\nbar\r\nbuzz"), []byte("\n"))
+	f.Add([]byte("foo\nbar\r\nbuzz"), []byte("\n\r"))
+	f.Add(bytes.Repeat([]byte("\x00\x80\xff\n"), 20), []byte{0x00, 0xff})
+	f.Add([]byte(strings.Repeat("a\n", 40)), []byte("a\n\rb\x85"))
+
+	f.Fuzz(func(t *testing.T, buf, targets []byte) {
+		requireSameAsNaive(t, buf, targets)
+	})
+}
+
+// ============================================================================
+//  Benchmarks
+// ============================================================================
+
+func BenchmarkCount(b *testing.B) {
+	buf := bytes.Repeat([]byte("0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcd\r\n"), 1024)
+
+	b.Run("bytes.Count_LF", func(b *testing.B) {
+		b.SetBytes(int64(len(buf)))
+
+		for range b.N {
+			_ = bytes.Count(buf, []byte("\n"))
+		}
+	})
+
+	b.Run("bytes.Count_LF_CR", func(b *testing.B) {
+		b.SetBytes(int64(len(buf)))
+
+		for range b.N {
+			_ = bytes.Count(buf, []byte("\n")) + bytes.Count(buf, []byte("\r"))
+		}
+	})
+
+	for _, targets := range []string{"\n", "\n\r"} {
+		b.Run(fmt.Sprintf("Count_%q", targets), func(b *testing.B) {
+			b.SetBytes(int64(len(buf)))
+
+			for range b.N {
+				_, _ = Count(buf, []byte(targets)...)
+			}
+		})
+
+		b.Run(fmt.Sprintf("countSWAR_%q", targets), func(b *testing.B) {
+			b.SetBytes(int64(len(buf)))
+
+			for range b.N {
+				_, _ = countSWAR(buf, []byte(targets))
+			}
+		})
+	}
+}
+
+// ============================================================================
+//  Helpers
+// ============================================================================
+
+// countNaive is the reference implementation of Count.
+func countNaive(buf []byte, targets []byte) (int, int) {
+	count, last := 0, -1
+
+	for index, value := range buf {
+		if slices.Contains(targets, value) {
+			count++
+			last = index
+		}
+	}
+
+	return count, last
+}
+
+// requireSameAsNaive requires all the implementations to return the same as the
+// naive loop.
+func requireSameAsNaive(t *testing.T, buf, targets []byte) {
+	t.Helper()
+
+	expectCount, expectLast := countNaive(buf, targets)
+
+	count, last := Count(buf, targets...)
+	require.Equal(t, expectCount, count, "Count: buf: %q, targets: %q", buf, targets)
+	require.Equal(t, expectLast, last, "Count: buf: %q, targets: %q", buf, targets)
+
+	count, last = countSWAR(buf, targets)
+	require.Equal(t, expectCount, count, "countSWAR: buf: %q, targets: %q", buf, targets)
+	require.Equal(t, expectLast, last, "countSWAR: buf: %q, targets: %q", buf, targets)
+
+	// The vectorized version only works with 1 to maxVectorTargets targets
+	if !useAVX2 || len(targets) == 0 || len(targets) > maxVectorTargets {
+		return
+	}
+
+	count, last = countBlocks(buf, targets)
+	require.Equal(t, expectCount, count, "countBlocks: buf: %q, targets: %q", buf, targets)
+	require.Equal(t, expectLast, last, "countBlocks: buf: %q, targets: %q", buf, targets)
+}
@@ -0,0 +1,258 @@
+package cl
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+//  Type: LineKinds
+// ----------------------------------------------------------------------------
+
+// LineKinds is the number of lines of each kind. A CR before the line break, as
+// in CRLF, is ignored to classify the line.
+type LineKinds struct {
+	// Empty is the number of lines without any character.
+	Empty int64
+	// Whitespace is the number of lines that consist of white spaces only. White
+	// spaces are the characters defined by unicode.IsSpace.
+	Whitespace int64
+	// Content is the number of lines with at least one non-space character.
+	Content int64
+}
+
+// Total returns the number of lines. It is the same as the result of CountLines.
+func (k LineKinds) Total() int64 {
+	return k.Empty + k.Whitespace + k.Content
+}
+
+// add returns the sum of the two.
+func (k LineKinds) add(other LineKinds) LineKinds {
+	return LineKinds{
+		Empty:      k.Empty + other.Empty,
+		Whitespace: k.Whitespace + other.Whitespace,
+		Content:    k.Content + other.Content,
+	}
+}
+
+// count adds a line of the given kind.
+func (k *LineKinds) count(kind lineKind) {
+	switch kind {
+	case kindEmpty:
+		k.Empty++
+	case kindBlank:
+		k.Whitespace++
+	case kindContent:
+		k.Content++
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  CountLineKinds
+// ----------------------------------------------------------------------------
+
+// CountLineKinds counts the empty lines, whitespace-only lines and the lines with
+// content in a single pass.
+//
+// The input is read in chunks and each chunk is scanned in parallel. The lines are
+// counted the same way as CountLines. The last line that does not end with a line
+// break is counted as well. Lines are not buffered, so the memory in use does not
+// depend on the length of the lines.
+func CountLineKinds(inputReader io.Reader) (LineKinds, error) {
+	if inputReader == nil {
+		return LineKinds{}, ErrNilReader
+	}
+
+	scanner := chunkScanner[kindsPartial]{
+		holdBack: holdBackRune,
+		scan:     scanLineKinds,
+		merge:    mergeLineKinds,
+	}
+
+	partial, err := scanner.scanStream(context.Background(), inputReader, numWorkers())
+	if err != nil {
+		if readErr := (*ReadError)(nil); errors.As(err, &readErr) {
+			readErr.Lines = partial.result().Total()
+		}
+
+		return LineKinds{}, err
+	}
+
+	return partial.result(), nil
+}
+
+// ----------------------------------------------------------------------------
+//  Type: kindsPartial
+// ----------------------------------------------------------------------------
+
+// kindsPartial is the mergeable line kinds of a chunk of the input.
+type kindsPartial struct {
+	// first is the segment of the line before the first line break. If the chunk
+	// has no line break, it is the segment of the whole chunk.
+	first kindSegment
+	// last is the segment of the line after the last line break.
+	last  kindSegment
+	kinds LineKinds // kinds of the lines between the first and last segment
+	hasLF bool      // true if the chunk has at least one line break
+}
+
+// scanLineKinds returns the line kinds of the chunk. The chunk must not end with
+// an incomplete UTF-8 sequence unless it is the end of the input.
+func scanLineKinds(chunk []byte) kindsPartial {
+	partial := kindsPartial{}
+
+	end := bytes.IndexByte(chunk, '\n')
+	if end < 0 {
+		partial.first = newKindSegment(chunk)
+
+		return partial
+	}
+
+	partial.hasLF = true
+	partial.first = newKindSegment(chunk[:end])
+	chunk = chunk[end+1:]
+
+	// Segments after the first line break are complete lines.
+	for end = bytes.IndexByte(chunk, '\n'); end >= 0; end = bytes.IndexByte(chunk, '\n') {
+		partial.kinds.count(newKindSegment(chunk[:end]).kind)
+		chunk = chunk[end+1:]
+	}
+
+	partial.last = newKindSegment(chunk)
+
+	return partial
+}
+
+// mergeLineKinds returns the line kinds of the "left" chunk followed by the
+// "right" chunk.
+func mergeLineKinds(left, right kindsPartial) kindsPartial {
+	merged := kindsPartial{
+		kinds: left.kinds.add(right.kinds),
+		hasLF: left.hasLF || right.hasLF,
+	}
+
+	switch {
+	case !left.hasLF:
+		merged.first = left.first.join(right.first)
+		merged.last = right.last
+	case !right.hasLF:
+		merged.first = left.first
+		merged.last = left.last.join(right.first)
+	default:
+		// The last line of the left and the first line of the right is a line.
+		merged.first = left.first
+		merged.last = right.last
+		merged.kinds.count(left.last.join(right.first).kind)
+	}
+
+	return merged
+}
+
+// result returns the line kinds of the whole input.
+func (p kindsPartial) result() LineKinds {
+	kinds := p.kinds
+	fragment := p.first
+
+	if p.hasLF {
+		kinds.count(p.first.kind)
+		fragment = p.last
+	}
+
+	// Count the last line without a line break. Trailing NULs are ignored the
+	// same as CountLines.
+	if fragment.hasNonNUL {
+		kinds.count(fragment.kind)
+	}
+
+	return kinds
+}
+
+// ----------------------------------------------------------------------------
+//  Type: kindSegment
+// ----------------------------------------------------------------------------
+
+// lineKind is the kind of a line. The greater kind wins when the parts of a line
+// are joined.
+type lineKind uint8
+
+const (
+	kindEmpty lineKind = iota
+	kindBlank
+	kindContent
+)
+
+// kindSegment is the kind of a part of a line. A CR at the end of the segment is
+// kept aside since it is ignored only if it is the end of the line.
+type kindSegment struct {
+	kind      lineKind // kind of the segment without the trailing CR
+	endsCR    bool     // true if the segment ends with a CR
+	hasNonNUL bool     // true if the segment has a byte other than NUL
+}
+
+// newKindSegment returns the segment of the given part of a line.
+func newKindSegment(segment []byte) kindSegment {
+	result := kindSegment{hasNonNUL: lastNonNULIndex(segment) >= 0}
+
+	if bytes.HasSuffix(segment, seqCR) {
+		result.endsCR = true
+		segment = segment[:len(segment)-1]
+	}
+
+	switch {
+	case len(segment) == 0:
+		result.kind = kindEmpty
+	case isBlank(segment):
+		result.kind = kindBlank
+	default:
+		result.kind = kindContent
+	}
+
+	return result
+}
+
+// join returns the segment of "s" followed by "next".
+func (s kindSegment) join(next kindSegment) kindSegment {
+	// The trailing CR is kept as is if nothing follows
+	if next.kind == kindEmpty && !next.endsCR {
+		return s
+	}
+
+	kind := max(s.kind, next.kind)
+
+	// The CR followed by something is a white space in the line
+	if s.endsCR {
+		kind = max(kind, kindBlank)
+	}
+
+	return kindSegment{
+		kind:      kind,
+		endsCR:    next.endsCR,
+		hasNonNUL: s.hasNonNUL || next.hasNonNUL,
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  Helper functions
+// ----------------------------------------------------------------------------
+
+// isBlank returns true if the line consists of white spaces only.
+func isBlank(line []byte) bool {
+	for index := 0; index < len(line); {
+		char, size := rune(line[index]), 1
+		if char >= utf8.RuneSelf {
+			char, size = utf8.DecodeRune(line[index:])
+		}
+
+		if !isSpace(char) {
+			return false
+		}
+
+		index += size
+	}
+
+	return true
+}
@@ -0,0 +1,162 @@
+package cl
+
+import (
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/KEINOS/go-countline/cl/spec"
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+//  Tests
+// ============================================================================
+
+func TestCountLineKinds_golden(t *testing.T) {
+	t.Parallel()
+
+	spec.RunSpecTest(t, "CountLineKinds", func(r io.Reader) (int, error) {
+		kinds, err := CountLineKinds(r)
+
+		return int(kinds.Total()), err
+	})
+}
+
+func TestCountLineKinds(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		input  string
+		expect LineKinds
+	}{
+		{input: "", expect: LineKinds{}},
+		{input: "\n", expect: LineKinds{Empty: 1}},
+		{input: " ", expect: LineKinds{Whitespace: 1}},
+		{input: "a", expect: LineKinds{Content: 1}},
+		{input: "a,b\n\n \t\n", expect: LineKinds{Empty: 1, Whitespace: 1, Content: 1}},
+		{input: "a,b\r\n\r\n \r\n", expect: LineKinds{Empty: 1, Whitespace: 1, Content: 1}},
+		{input: "\u3000 \n日本\n", expect: LineKinds{Whitespace: 1, Content: 1}},
+		{input: "{}\n{}\n\n\n", expect: LineKinds{Empty: 2, Content: 2}},
+		{input: "{}\n\x00\x00", expect: LineKinds{Content: 1}},
+		{input: "{}\n\x00a", expect: LineKinds{Content: 2}},
+		{input: "\xff\n", expect: LineKinds{Content: 1}},
+	} {
+		for name, reader := range map[string]io.Reader{
+			"whole":    strings.NewReader(test.input),
+			"one byte": iotest.OneByteReader(strings.NewReader(test.input)),
+		} {
+			actual, err := CountLineKinds(reader)
+
+			require.NoError(t, err)
+			require.Equal(t, test.expect, actual, "input: %q, reader: %s", test.input, name)
+		}
+	}
+}
+
+func TestCountLineKinds_large(t *testing.T) {
+	t.Parallel()
+
+	const numLines = 100000
+
+	var builder strings.Builder
+
+	for i := range numLines {
+		switch i % 4 {
+		case 0:
+			builder.WriteString("\n")
+		case 1:
+			builder.WriteString(strings.Repeat(" ", i%50) + " \n")
+		default:
+			builder.WriteString(strings.Repeat(" ", i%50) + "data\n")
+		}
+	}
+
+	actual, err := CountLineKinds(strings.NewReader(builder.String()))
+
+	require.NoError(t, err)
+	require.Equal(t, LineKinds{Empty: numLines / 4, Whitespace: numLines / 4, Content: numLines / 2}, actual)
+}
+
+// The line kinds must be the same regardless of where the input is split into
+// chunks, e.g. between CR and LF or in the middle of a line.
+func Test_mergeLineKinds(t *testing.T) {
+	t.Parallel()
+
+	for _, input := range []string{
+		"a,b\r\n\r\n \r\n",
+		"\r\r\n \r \n\ra\n\r",
+		"\u3000 \n日本\n",
+		"{}\n\x00\x00",
+		"{}\n\x00a\n\x00",
+		"  \t\n\n\nfoo",
+	} {
+		expect := scanLineKinds([]byte(input)).result()
+
+		for split := range len(input) + 1 {
+			left, right := []byte(input[:split]), []byte(input[split:])
+
+			// Split runes are carried over to the next chunk the same as scanStream
+			hold := holdBackRune(left)
+			left, right = left[:len(left)-hold], append(left[len(left)-hold:], right...)
+
+			actual := mergeLineKinds(scanLineKinds(left), scanLineKinds(right)).result()
+
+			require.Equal(t, expect, actual, "input: %q, split at: %d", input, split)
+		}
+	}
+}
+
+func TestCountLineKinds_long_line(t *testing.T) {
+	if isRaceEnabled {
+		t.Skip("sync.Pool does not reuse the buffers reliably under the race detector")
+	}
+
+	const sizeInput = 256 * 1024 * 1024 // 256 MiB in a single line
+
+	// Buffers in use at the same time plus some room for the runtime.
+	ceiling := uint64((2*numWorkers()+1)*chunkSize + 1024*1024)
+
+	reader := &RepeatReader{
+		line:   []byte(strings.Repeat(" ", 4095) + "a"),
+		remain: sizeInput,
+	}
+
+	var before, after runtime.MemStats
+
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	kinds, err := CountLineKinds(reader)
+
+	runtime.ReadMemStats(&after)
+
+	require.NoError(t, err)
+	require.Equal(t, LineKinds{Content: 1}, kinds)
+
+	allocated := after.TotalAlloc - before.TotalAlloc
+
+	t.Logf("allocated: %d bytes for a line of %d bytes", allocated, sizeInput)
+	require.Less(t, allocated, ceiling,
+		"allocated memory should not depend on the length of the line")
+}
+
+func TestCountLineKinds_errors(t *testing.T) {
+	t.Parallel()
+
+	kinds, err := CountLineKinds(nil)
+
+	require.ErrorIs(t, err, ErrNilReader)
+	require.Zero(t, kinds)
+
+	kinds, err = CountLineKinds(&FailAfterReader{reader: strings.NewReader("foo\n\n")})
+
+	var readErr *ReadError
+
+	require.ErrorAs(t, err, &readErr)
+	require.Equal(t, int64(5), readErr.Offset)
+	require.Equal(t, int64(2), readErr.Lines)
+	require.Zero(t, kinds)
+}
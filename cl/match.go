@@ -0,0 +1,203 @@
+package cl
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+//  Type: Matcher
+// ----------------------------------------------------------------------------
+
+// Matcher reports whether a line matches. The line does not include the line
+// break.
+//
+// *regexp.Regexp satisfies this interface. Use Literal for a fixed pattern which
+// is much faster than the regular expression.
+type Matcher interface {
+	Match(line []byte) bool
+}
+
+// Literal is a Matcher of a fixed byte pattern. A line matches if it contains the
+// pattern. An empty pattern matches any line.
+type Literal []byte
+
+// Match reports whether the line contains the pattern.
+func (l Literal) Match(line []byte) bool {
+	return bytes.Contains(line, l)
+}
+
+// count returns the number of lines in the chunk that contain the pattern.
+//
+// Instead of matching line by line, it searches the pattern through the chunk and
+// skips to the next line on each match.
+func (l Literal) count(chunk []byte) int64 {
+	if bytes.IndexByte(l, '\n') >= 0 {
+		return 0 // never matches a line
+	}
+
+	matched := int64(0)
+
+	for pos := 0; pos < len(chunk); {
+		found := bytes.Index(chunk[pos:], l)
+		if found < 0 {
+			break
+		}
+
+		matched++
+
+		// The pattern never contains a line break. So the line ends after it.
+		end := bytes.IndexByte(chunk[pos+found+len(l):], '\n')
+		if end < 0 {
+			break
+		}
+
+		pos += found + len(l) + end + 1
+	}
+
+	return matched
+}
+
+// Invert returns a Matcher that matches the lines that the given matcher does not
+// match, the same as "grep -v".
+func Invert(matcher Matcher) Matcher {
+	return inverted{matcher: matcher}
+}
+
+// inverted is the Matcher returned by Invert.
+type inverted struct {
+	matcher Matcher
+}
+
+// Match reports whether the line does not match the underlying matcher.
+func (i inverted) Match(line []byte) bool {
+	return !i.matcher.Match(line)
+}
+
+// ----------------------------------------------------------------------------
+//  CountMatching
+// ----------------------------------------------------------------------------
+
+// CountMatching counts the number of lines that match the matcher, the same as
+// "grep -c".
+//
+// The input is read in chunks and each chunk is scanned in parallel. A line split
+// across the chunks is carried over to the next chunk and matched as a whole. The
+// last line that does not end with a line break is matched as well.
+func CountMatching(inputReader io.Reader, matcher Matcher) (int, error) {
+	count, err := CountMatching64(inputReader, matcher)
+	if err != nil {
+		return 0, err
+	}
+
+	return toInt(count)
+}
+
+// ----------------------------------------------------------------------------
+//  CountMatching64
+// ----------------------------------------------------------------------------
+
+// CountMatching64 is the same as CountMatching but returns the number of lines as
+// uint64.
+func CountMatching64(inputReader io.Reader, matcher Matcher) (uint64, error) {
+	if inputReader == nil {
+		return 0, ErrNilReader
+	}
+
+	if matcher == nil {
+		return 0, errors.New("given matcher is nil")
+	}
+
+	scanner := chunkScanner[matchPartial]{
+		holdBack: holdBackLine,
+		scan: func(chunk []byte) matchPartial {
+			return scanMatch(chunk, matcher)
+		},
+		merge: func(left, right matchPartial) matchPartial {
+			return matchPartial{lines: left.lines + right.lines, matched: left.matched + right.matched}
+		},
+	}
+
+	partial, err := scanner.scanStream(context.Background(), inputReader, numWorkers())
+	if err != nil {
+		if readErr := (*ReadError)(nil); errors.As(err, &readErr) {
+			readErr.Lines = partial.lines
+		}
+
+		return 0, err
+	}
+
+	if _, ok := matcher.(inverted); ok {
+		return uint64(partial.lines - partial.matched), nil //nolint:gosec // matched never exceeds lines
+	}
+
+	return uint64(partial.matched), nil //nolint:gosec // never negative
+}
+
+// matchPartial is the number of lines and the matched lines of a chunk.
+type matchPartial struct {
+	lines   int64
+	matched int64
+}
+
+// scanMatch counts the lines of the chunk that match the matcher. The chunk must
+// consist of complete lines except the end of the input.
+//
+// For the inverted matcher, it counts the lines that match the underlying matcher
+// to use the fast path of Literal.
+func scanMatch(chunk []byte, matcher Matcher) matchPartial {
+	if inv, ok := matcher.(inverted); ok {
+		matcher = inv.matcher
+	}
+
+	chunk = trimNULFragment(chunk)
+
+	//nolint:gosec // bytes.Count never returns a negative value
+	partial := matchPartial{lines: int64(bytes.Count(chunk, seqLF))}
+
+	// The last line without a line break
+	if len(chunk) > 0 && chunk[len(chunk)-1] != '\n' {
+		partial.lines++
+	}
+
+	if literal, ok := matcher.(Literal); ok {
+		partial.matched = literal.count(chunk)
+
+		return partial
+	}
+
+	for len(chunk) > 0 {
+		end := bytes.IndexByte(chunk, '\n')
+		if end < 0 {
+			end = len(chunk)
+		}
+
+		if matcher.Match(chunk[:end]) {
+			partial.matched++
+		}
+
+		chunk = chunk[min(end+1, len(chunk)):]
+	}
+
+	return partial
+}
+
+// holdBackLine returns the length of the incomplete line at the end of the chunk
+// to be carried over to the next chunk.
+func holdBackLine(chunk []byte) int {
+	return len(chunk) - (bytes.LastIndexByte(chunk, '\n') + 1)
+}
+
+// trimNULFragment removes the last line without a line break if it consists of
+// NULs only. Trailing NULs are not a line the same as CountLines.
+func trimNULFragment(chunk []byte) []byte {
+	fragment := chunk[bytes.LastIndexByte(chunk, '\n')+1:]
+	if lastNonNULIndex(fragment) < 0 {
+		return chunk[:len(chunk)-len(fragment)]
+	}
+
+	return chunk
+}
@@ -0,0 +1,149 @@
+package cl
+
+import (
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/KEINOS/go-countline/cl/spec"
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+//  Tests
+// ============================================================================
+
+func TestCountMatching_golden(t *testing.T) {
+	t.Parallel()
+
+	// Matchers that match any line should count the same as CountLines
+	for name, matcher := range map[string]Matcher{
+		"empty literal":       Literal(""),
+		"empty regexp":        regexp.MustCompile(""),
+		"inverted never":      Invert(Literal("\n")),
+		"inverted never (re)": Invert(regexp.MustCompile(`\n`)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			spec.RunSpecTest(t, "CountMatching", func(r io.Reader) (int, error) {
+				return CountMatching(r, matcher)
+			})
+		})
+	}
+}
+
+func TestCountMatching(t *testing.T) {
+	t.Parallel()
+
+	const input = "INFO start\nERROR foo\n\nWARN bar ERROR\nERROR\nINFO end\x00ERROR\nERROR buzz"
+
+	for _, test := range []struct {
+		matcher Matcher
+		name    string
+		expect  int
+	}{
+		{name: "literal", matcher: Literal("ERROR"), expect: 5},
+		{name: "literal at line head", matcher: regexp.MustCompile(`^ERROR`), expect: 3},
+		{name: "regexp", matcher: regexp.MustCompile(`ERROR\s\w+`), expect: 2},
+		{name: "inverted literal", matcher: Invert(Literal("ERROR")), expect: 2},
+		{name: "inverted regexp", matcher: Invert(regexp.MustCompile(`^ERROR`)), expect: 4},
+		{name: "empty lines", matcher: regexp.MustCompile(`^$`), expect: 1},
+		{name: "literal with NUL", matcher: Literal("\x00"), expect: 1},
+		{name: "literal with LF", matcher: Literal("foo\n"), expect: 0},
+		{name: "no match", matcher: Literal("DEBUG"), expect: 0},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			for name, reader := range map[string]io.Reader{
+				"whole":    strings.NewReader(input),
+				"one byte": iotest.OneByteReader(strings.NewReader(input)),
+				"half":     iotest.HalfReader(strings.NewReader(input)),
+			} {
+				actual, err := CountMatching(reader, test.matcher)
+
+				require.NoError(t, err)
+				require.Equal(t, test.expect, actual, "reader: %s", name)
+			}
+		})
+	}
+}
+
+func TestCountMatching_large(t *testing.T) {
+	t.Parallel()
+
+	// Lines of various lengths to split the lines across the chunks
+	var builder strings.Builder
+
+	expect := 0
+
+	for i := range 50000 {
+		line := strings.Repeat("x", i%37)
+		if i%3 == 0 {
+			line += "needle"
+			expect++
+		}
+
+		builder.WriteString(line + "\n")
+	}
+
+	input := builder.String()
+
+	require.Greater(t, len(input), chunkSize*10)
+
+	for _, matcher := range []Matcher{Literal("needle"), regexp.MustCompile("needle")} {
+		actual, err := CountMatching64(strings.NewReader(input), matcher)
+
+		require.NoError(t, err)
+		require.Equal(t, uint64(expect), actual, "matcher: %T", matcher)
+
+		actual, err = CountMatching64(strings.NewReader(input), Invert(matcher))
+
+		require.NoError(t, err)
+		require.Equal(t, uint64(50000-expect), actual, "matcher: inverted %T", matcher)
+	}
+}
+
+func TestCountMatching_trailing_nul(t *testing.T) {
+	t.Parallel()
+
+	actual, err := CountMatching(strings.NewReader("foo\nbar\n\x00\x00"), Invert(Literal("foo")))
+
+	require.NoError(t, err)
+	require.Equal(t, 1, actual, "trailing NULs should not be a line the same as CountLines")
+}
+
+func TestCountMatching_errors(t *testing.T) {
+	t.Parallel()
+
+	count, err := CountMatching(nil, Literal("foo"))
+
+	require.ErrorIs(t, err, ErrNilReader)
+	require.Zero(t, count)
+
+	count, err = CountMatching(strings.NewReader("foo"), nil)
+
+	require.ErrorContains(t, err, "given matcher is nil")
+	require.Zero(t, count)
+
+	count, err = CountMatching(&FailAfterReader{reader: strings.NewReader("foo\nbar\n")}, Literal("foo"))
+
+	var readErr *ReadError
+
+	require.ErrorAs(t, err, &readErr)
+	require.Equal(t, int64(8), readErr.Offset)
+	require.Equal(t, int64(2), readErr.Lines)
+	require.Zero(t, count)
+}
+
+func TestLiteral_Match(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, Literal("foo").Match([]byte("foobar")))
+	require.False(t, Literal("foo").Match([]byte("bar")))
+	require.True(t, Literal("").Match([]byte("")))
+	require.True(t, Invert(Literal("foo")).Match([]byte("bar")))
+}
@@ -0,0 +1,46 @@
+//go:build linux
+
+package cl
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+// mmapFile maps the whole file into memory read-only. The returned function must
+// be called to unmap the memory after use.
+//
+// It returns an error if the file is not a regular file, is empty or is larger
+// than the maximum value of int.
+func mmapFile(osFile *os.File) ([]byte, func(), error) {
+	info, err := osFile.Stat()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get file info")
+	}
+
+	// Special files (pipes, devices, procfs, etc.) can not be mapped reliably.
+	if !info.Mode().IsRegular() || info.Size() == 0 || uint64(info.Size()) > uint64(maxInt) {
+		return nil, nil, errors.New("file is not mappable")
+	}
+
+	data, err := syscall.Mmap(
+		int(osFile.Fd()), //nolint:gosec // file descriptors fit in int
+		0,
+		int(info.Size()),
+		syscall.PROT_READ,
+		syscall.MAP_SHARED,
+	)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to mmap file")
+	}
+
+	// Hint the kernel to read ahead aggressively. The error is ignored since it
+	// is only an advice.
+	_ = syscall.Madvise(data, syscall.MADV_SEQUENTIAL)
+
+	return data, func() {
+		_ = syscall.Munmap(data)
+	}, nil
+}
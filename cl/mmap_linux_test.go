@@ -0,0 +1,83 @@
+//go:build linux
+
+package cl
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountLinesFile_procfs(t *testing.T) {
+	t.Parallel()
+
+	// Files in procfs are reported as empty and can not be mapped.
+	numLines, err := CountLinesFile("/proc/self/status")
+
+	require.NoError(t, err, "it should fall back to the reader")
+	require.Positive(t, numLines)
+}
+
+func Test_mmapFile_closed_file(t *testing.T) {
+	t.Parallel()
+
+	osFile, err := os.Open(filepath.Join("testdata", "README.md"))
+	require.NoError(t, err)
+	require.NoError(t, osFile.Close())
+
+	data, unmap, err := mmapFile(osFile)
+
+	require.Error(t, err)
+	require.Nil(t, data)
+	require.Nil(t, unmap)
+	require.Contains(t, err.Error(), "failed to get file info")
+}
+
+func Test_mmapFile_write_only(t *testing.T) {
+	t.Parallel()
+
+	pathFile := filepath.Join(t.TempDir(), "data.txt")
+	require.NoError(t, os.WriteFile(pathFile, []byte("Hello\n"), 0o600))
+
+	osFile, err := os.OpenFile(pathFile, os.O_WRONLY, 0)
+	require.NoError(t, err)
+
+	defer osFile.Close()
+
+	_, _, err = mmapFile(osFile)
+
+	require.Error(t, err, "write-only file should fail to be mapped for reading")
+	require.Contains(t, err.Error(), "failed to mmap file")
+}
+
+func Test_countMapped_truncated_file(t *testing.T) {
+	t.Parallel()
+
+	pathFile := filepath.Join(t.TempDir(), "data.txt")
+	input := strings.Repeat("Hello\n", chunkSize)
+
+	require.NoError(t, os.WriteFile(pathFile, []byte(input), 0o600))
+
+	osFile, err := os.Open(pathFile)
+	require.NoError(t, err)
+
+	defer osFile.Close()
+
+	data, unmap, err := mmapFile(osFile)
+	require.NoError(t, err)
+
+	defer unmap()
+
+	// Truncate the file after mapping. Accessing the mapped region beyond the
+	// end of the file raises SIGBUS.
+	require.NoError(t, os.Truncate(pathFile, 0))
+
+	count, err := countMapped(data, 4)
+
+	require.Error(t, err, "fault on the mapped region should be returned as an error")
+	require.Zero(t, count)
+	require.Contains(t, err.Error(), "failed to read the mapped file")
+}
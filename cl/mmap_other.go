@@ -0,0 +1,15 @@
+//go:build !linux
+
+package cl
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// mmapFile is not supported on this platform. It always returns an error to let
+// the caller fall back to the reader.
+func mmapFile(_ *os.File) ([]byte, func(), error) {
+	return nil, nil, errors.New("mmap is not supported on this platform")
+}
@@ -0,0 +1,163 @@
+package cl
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/KEINOS/go-countline/cl/strategy"
+	"github.com/pkg/errors"
+)
+
+// Register CountLines as the default strategy to be looked up by the name.
+//
+//nolint:gochecknoinits // the registry can not import this package due to the cycle
+func init() {
+	strategy.Register(strategy.Default, strategy.Func(CountLines))
+}
+
+// ----------------------------------------------------------------------------
+//  Type: Options
+// ----------------------------------------------------------------------------
+
+// Options are the options to count the lines. The zero value is the same as the
+// behavior of CountLines.
+type Options struct {
+	// Terminator is the set of line terminators to count. Default is LF.
+	Terminator Terminator
+	// Progress is called periodically with the number of bytes read and the
+	// number of lines counted so far, and once more with the final number of
+	// lines on success. It is never called concurrently.
+	//
+	// Since the chunks are counted concurrently, "linesSoFar" may lag behind the
+	// "bytesRead".
+	Progress func(bytesRead, linesSoFar int64)
+	// ProgressBytes is the interval in bytes to call Progress.
+	ProgressBytes int64
+	// ProgressInterval is the interval in time to call Progress. If neither
+	// ProgressBytes nor ProgressInterval is set, it is 100 milliseconds.
+	ProgressInterval time.Duration
+	// DebugHook is called once with the path chosen to count the input and the
+	// size of the input, or -1 if the size is unknown. It is for debugging and
+	// tuning. It is not called with Strategy.
+	DebugHook func(path Path, size int64)
+	// Strategy is the implementation to count the lines instead of the built-in
+	// one. See the cl/strategy package for the registered ones. It counts only LF
+	// the same as CountLines, so it can not be used with the other options. It is
+	// ignored by Counter.
+	Strategy strategy.Strategy
+	// WcCompat counts only the terminators as "wc -l" does. The last line that
+	// does not end with a terminator is not counted.
+	WcCompat bool
+}
+
+// WithStrategy returns the options to count the lines with the given strategy. It
+// is the same as Options{Strategy: s}.
+//
+//	s, _ := strategy.Lookup(strategy.ByteLoop)
+//	count, err := cl.CountLinesWithOptions(input, cl.WithStrategy(s))
+func WithStrategy(s strategy.Strategy) Options {
+	return Options{Strategy: s}
+}
+
+// normalize returns the options with the default values set.
+func (o Options) normalize() Options {
+	o.Terminator = o.Terminator.orDefault()
+
+	return o
+}
+
+// notifyPath calls the debug hook if set.
+func (o Options) notifyPath(path Path, size int64) {
+	if o.DebugHook != nil {
+		o.DebugHook(path, size)
+	}
+}
+
+// hasFragment returns true if the input ends without a terminator and the last
+// line should be counted.
+func (o Options) hasFragment(trail *trailer) bool {
+	return !o.WcCompat && trail.hasFragment(o.Terminator)
+}
+
+// ----------------------------------------------------------------------------
+//  CountLinesWithOptions
+// ----------------------------------------------------------------------------
+
+// CountLinesWithOptions is the same as CountLines but counts the lines with the
+// given options.
+//
+// A terminator split across the chunks of the input, such as "\r" and "\n" of a
+// CRLF, is counted only once.
+func CountLinesWithOptions(inputReader io.Reader, opts Options) (int, error) {
+	count, err := CountLinesWithOptions64(inputReader, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	return toInt(count)
+}
+
+// ----------------------------------------------------------------------------
+//  CountLinesWithOptions64
+// ----------------------------------------------------------------------------
+
+// CountLinesWithOptions64 is the same as CountLinesWithOptions but returns the
+// number of lines as uint64.
+func CountLinesWithOptions64(inputReader io.Reader, opts Options) (uint64, error) {
+	if inputReader == nil {
+		return 0, ErrNilReader
+	}
+
+	if opts.Strategy != nil {
+		return countStrategy(inputReader, opts)
+	}
+
+	count, err := countReader(context.Background(), inputReader, opts.normalize())
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// countStrategy counts the lines with the strategy of the options.
+func countStrategy(inputReader io.Reader, opts Options) (uint64, error) {
+	if opts.Terminator.orDefault() != LF || opts.WcCompat || opts.Progress != nil {
+		return 0, errors.New("strategy can not be used with Terminator, WcCompat or Progress options")
+	}
+
+	count, err := opts.Strategy.CountLines(inputReader)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to count lines with the strategy")
+	}
+
+	if count < 0 {
+		return 0, errors.Errorf("strategy returned a negative number of lines: %d", count)
+	}
+
+	return uint64(count), nil
+}
+
+// ----------------------------------------------------------------------------
+//  CountNewlines
+// ----------------------------------------------------------------------------
+
+// CountNewlines counts the number of line breaks (LF) in the input, the same as
+// "wc -l" command.
+//
+// Unlike CountLines, the last line that does not end with a line break is not
+// counted. It is a shorthand of CountLinesWithOptions with WcCompat option.
+func CountNewlines(inputReader io.Reader) (int, error) {
+	return CountLinesWithOptions(inputReader, Options{WcCompat: true})
+}
+
+// ----------------------------------------------------------------------------
+//  CountNewlines64
+// ----------------------------------------------------------------------------
+
+// CountNewlines64 is the same as CountNewlines but returns the number of line
+// breaks as uint64.
+func CountNewlines64(inputReader io.Reader) (uint64, error) {
+	return CountLinesWithOptions64(inputReader, Options{WcCompat: true})
+}
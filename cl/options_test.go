@@ -0,0 +1,255 @@
+package cl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/KEINOS/go-countline/cl/spec"
+	"github.com/KEINOS/go-countline/cl/strategy"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+//  Tests
+// ============================================================================
+
+func TestCountLinesWithOptions_golden(t *testing.T) {
+	t.Parallel()
+
+	spec.RunSpecTest(t, "CountLinesWithOptions", func(r io.Reader) (int, error) {
+		return CountLinesWithOptions(r, Options{})
+	})
+}
+
+func TestCountLinesWithOptions64_golden(t *testing.T) {
+	t.Parallel()
+
+	spec.RunSpecTest64(t, "CountLinesWithOptions64", func(r io.Reader) (uint64, error) {
+		return CountLinesWithOptions64(r, Options{})
+	})
+}
+
+func TestCountLinesWithOptions_terminator(t *testing.T) {
+	t.Parallel()
+
+	spec.RunSpecTestTerminator(t, "CountLinesWithOptions", func(terminator string, r io.Reader) (int, error) {
+		return CountLinesWithOptions(r, Options{Terminator: parseTerminator(t, terminator)})
+	})
+}
+
+func TestCountLinesWithOptions_terminator_stream(t *testing.T) {
+	t.Parallel()
+
+	for _, numWorker := range []int{1, 4} {
+		nameFn := fmt.Sprintf("countStream_%d_workers", numWorker)
+
+		spec.RunSpecTestTerminator(t, nameFn, func(terminator string, r io.Reader) (int, error) {
+			count, err := countStream(context.Background(), r, numWorker, Options{Terminator: parseTerminator(t, terminator)})
+
+			return int(count), err //nolint:gosec // small number of lines in spec tests
+		})
+	}
+
+	// Read byte by byte to split every terminator across chunks
+	spec.RunSpecTestTerminator(t, "countStream_one_byte", func(terminator string, r io.Reader) (int, error) {
+		count, err := countStream(
+			context.Background(), iotest.OneByteReader(r), 2, Options{Terminator: parseTerminator(t, terminator)},
+		)
+
+		return int(count), err //nolint:gosec // small number of lines in spec tests
+	})
+}
+
+func TestCountLinesWithOptions_terminator_sections(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		term   Terminator
+		split  string
+		expect uint64
+	}{
+		{term: CRLF, split: "\r\n", expect: 2},
+		{term: LF | CRLF | CR, split: "\r\n", expect: 2},
+		{term: Unicode, split: "\u2028", expect: 2},
+		{term: Unicode, split: "\u0085", expect: 2},
+	} {
+		// Place the terminator at the boundary of the 2 sections
+		for offset := 1; offset < len(test.split); offset++ {
+			head := strings.Repeat("a", chunkSize-offset) + test.split
+			input := head + strings.Repeat("b", chunkSize*2-len(head))
+
+			count, err := countReaderAt(
+				context.Background(), strings.NewReader(input), int64(len(input)), 2, Options{Terminator: test.term},
+			)
+
+			require.NoError(t, err)
+			require.Equal(t, test.expect, count, "terminator: %v, split: %q, offset: %d", test.term, test.split, offset)
+		}
+	}
+}
+
+func TestCountNewlines_golden(t *testing.T) {
+	t.Parallel()
+
+	spec.RunSpecTestNewlines(t, "CountNewlines", CountNewlines)
+}
+
+func TestCountNewlines64_golden(t *testing.T) {
+	t.Parallel()
+
+	spec.RunSpecTestNewlines(t, "CountNewlines64", func(r io.Reader) (int, error) {
+		count, err := CountNewlines64(r)
+
+		return int(count), err //nolint:gosec // expected numbers of the specs are small
+	})
+}
+
+func TestCountLinesWithOptions_wc_compat(t *testing.T) {
+	t.Parallel()
+
+	opts := Options{WcCompat: true}.normalize()
+
+	spec.RunSpecTestNewlines(t, "countStream_wc_compat", func(r io.Reader) (int, error) {
+		count, err := countStream(context.Background(), iotest.OneByteReader(r), 2, opts)
+
+		return int(count), err //nolint:gosec // small number of lines in spec tests
+	})
+
+	spec.RunSpecTestNewlines(t, "countReaderAt_wc_compat", func(r io.Reader) (int, error) {
+		reader, ok := r.(*strings.Reader)
+		require.True(t, ok, "spec test should provide *strings.Reader")
+
+		count, err := countReaderAt(context.Background(), reader, reader.Size(), 4, opts)
+
+		return int(count), err //nolint:gosec // small number of lines in spec tests
+	})
+
+	for _, test := range []struct {
+		input  string
+		term   Terminator
+		expect int
+	}{
+		{term: CRLF, input: "Hello\r\nWorld", expect: 1},
+		{term: CRLF, input: "Hello\r\nWorld\r\n", expect: 2},
+		{term: CR, input: "Hello\rWorld\r\n", expect: 2},
+		{term: Unicode, input: "Hello\u2028World", expect: 1},
+	} {
+		numLines, err := CountLinesWithOptions(strings.NewReader(test.input), Options{Terminator: test.term, WcCompat: true})
+
+		require.NoError(t, err)
+		require.Equal(t, test.expect, numLines, "term: %v, input: %q", test.term, test.input)
+	}
+}
+
+func TestCountLinesWithOptions_nil_input(t *testing.T) {
+	t.Parallel()
+
+	numLines, err := CountLinesWithOptions(nil, Options{})
+
+	require.Error(t, err)
+	require.Equal(t, 0, numLines, "returned number of lines should be 0 on error")
+	require.Contains(t, err.Error(), "given reader is nil")
+}
+
+func TestCountLinesWithOptions_io_read_fail(t *testing.T) {
+	t.Parallel()
+
+	numLines, err := CountLinesWithOptions(&DummyReader{}, Options{Terminator: CRLF})
+
+	require.Error(t, err)
+	require.Equal(t, 0, numLines, "returned number of lines should be 0 on error")
+	require.Contains(t, err.Error(), "forced error", "the error should contain the reason of the error")
+}
+
+func TestWithStrategy_golden(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range strategy.Names() {
+		found, ok := strategy.Lookup(name)
+		require.True(t, ok, name)
+
+		spec.RunSpecTest(t, "WithStrategy_"+name, func(r io.Reader) (int, error) {
+			return CountLinesWithOptions(r, WithStrategy(found))
+		})
+	}
+}
+
+func TestWithStrategy_default(t *testing.T) {
+	t.Parallel()
+
+	found, ok := strategy.Lookup(strategy.Default)
+
+	require.True(t, ok, "CountLines should be registered as the default strategy")
+
+	numLines, err := found.CountLines(strings.NewReader("foo\nbar"))
+
+	require.NoError(t, err)
+	require.Equal(t, 2, numLines)
+}
+
+func TestWithStrategy_fail(t *testing.T) {
+	t.Parallel()
+
+	errForced := errors.New("forced error")
+
+	for _, test := range []struct {
+		opts   Options
+		expect string
+	}{
+		{
+			opts:   Options{Strategy: strategy.Func(CountLines), Terminator: CRLF},
+			expect: "strategy can not be used with Terminator, WcCompat or Progress options",
+		},
+		{
+			opts:   Options{Strategy: strategy.Func(CountLines), WcCompat: true},
+			expect: "strategy can not be used",
+		},
+		{
+			opts:   Options{Strategy: strategy.Func(CountLines), Progress: func(_, _ int64) {}},
+			expect: "strategy can not be used",
+		},
+		{
+			opts:   WithStrategy(strategy.Func(func(io.Reader) (int, error) { return 0, errForced })),
+			expect: "failed to count lines with the strategy: forced error",
+		},
+		{
+			opts:   WithStrategy(strategy.Func(func(io.Reader) (int, error) { return -1, nil })),
+			expect: "strategy returned a negative number of lines: -1",
+		},
+	} {
+		numLines, err := CountLinesWithOptions(strings.NewReader("foo\n"), test.opts)
+
+		require.ErrorContains(t, err, test.expect)
+		require.Zero(t, numLines, "returned number of lines should be 0 on error")
+	}
+
+	// The terminator set to LF explicitly is the same as the default
+	numLines, err := CountLinesWithOptions(strings.NewReader("foo\n"), Options{Strategy: strategy.Func(CountLines), Terminator: LF})
+
+	require.NoError(t, err)
+	require.Equal(t, 1, numLines)
+}
+
+// ============================================================================
+//  Helpers
+// ============================================================================
+
+// parseTerminator returns the Terminator of the given names joined with "|".
+func parseTerminator(t *testing.T, names string) Terminator {
+	t.Helper()
+
+	for term := LF; term <= LF|CRLF|CR|Unicode; term++ {
+		if term.String() == names {
+			return term
+		}
+	}
+
+	t.Fatalf("unknown terminator: %v", names)
+
+	return 0
+}
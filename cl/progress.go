@@ -0,0 +1,100 @@
+package cl
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultProgressInterval is the interval to call the progress callback if no
+// interval is set in the options.
+const defaultProgressInterval = 100 * time.Millisecond
+
+// ----------------------------------------------------------------------------
+//  Type: reporter
+// ----------------------------------------------------------------------------
+
+// reporter calls the progress callback of the options at the given intervals.
+//
+// The methods are safe for concurrent use by the reader and the workers, and the
+// callback is never called concurrently. A nil reporter does nothing.
+type reporter struct {
+	nextTime   time.Time
+	fn         func(bytesRead, linesSoFar int64)
+	everyTime  time.Duration
+	everyBytes int64
+	nextBytes  int64
+	bytesRead  int64
+	lines      atomic.Uint64
+	mu         sync.Mutex
+}
+
+// newReporter returns a new reporter for the options. It returns nil if no
+// progress callback is set.
+func newReporter(opts Options) *reporter {
+	if opts.Progress == nil {
+		return nil
+	}
+
+	rep := &reporter{
+		fn:         opts.Progress,
+		everyBytes: opts.ProgressBytes,
+		everyTime:  opts.ProgressInterval,
+	}
+
+	if rep.everyBytes <= 0 && rep.everyTime <= 0 {
+		rep.everyTime = defaultProgressInterval
+	}
+
+	rep.nextBytes = rep.everyBytes
+	rep.nextTime = time.Now().Add(rep.everyTime)
+
+	return rep
+}
+
+// addLines adds the number of lines counted by a worker.
+func (r *reporter) addLines(numLines uint64) {
+	if r == nil {
+		return
+	}
+
+	r.lines.Add(numLines)
+}
+
+// addBytes adds the number of bytes read and calls the callback if the interval
+// has passed.
+func (r *reporter) addBytes(numRead int) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.bytesRead += int64(numRead)
+
+	dueBytes := r.everyBytes > 0 && r.bytesRead >= r.nextBytes
+	dueTime := r.everyTime > 0 && !time.Now().Before(r.nextTime)
+
+	if !dueBytes && !dueTime {
+		return
+	}
+
+	//nolint:gosec // number of lines never exceeds the max value of int64 in practice
+	r.fn(r.bytesRead, int64(r.lines.Load()))
+
+	r.nextBytes = r.bytesRead + r.everyBytes
+	r.nextTime = time.Now().Add(r.everyTime)
+}
+
+// done calls the callback with the final number of lines.
+func (r *reporter) done(numLines uint64) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.fn(r.bytesRead, int64(numLines)) //nolint:gosec // same as addBytes
+}
@@ -0,0 +1,145 @@
+package cl
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/KEINOS/go-countline/cl/spec"
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+//  Tests
+// ============================================================================
+
+func TestCountLinesWithOptions_progress_bytes(t *testing.T) {
+	t.Parallel()
+
+	const numLines = chunkSize / 8 * 10 // 10 chunks of 8 bytes lines
+
+	input := spec.GetStrDummyLines(8, numLines)
+
+	for name, reader := range map[string]func() io.Reader{
+		// Wrapping hides io.ReaderAt to be read as a stream
+		"stream":    func() io.Reader { return struct{ io.Reader }{strings.NewReader(input)} },
+		"reader_at": func() io.Reader { return strings.NewReader(input) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			recorder := new(ProgressRecorder)
+
+			numLinesActual, err := CountLinesWithOptions(reader(), Options{
+				Progress:      recorder.Record,
+				ProgressBytes: chunkSize * 3,
+			})
+
+			require.NoError(t, err)
+			require.Equal(t, numLines, numLinesActual)
+
+			calls := recorder.Calls()
+
+			require.Len(t, calls, 4, "it should be called every 3 chunks and once at the end")
+
+			for index, call := range calls[:3] {
+				require.Equal(t, int64(chunkSize*3*(index+1)), call[0], "bytes read should increase by the interval")
+				require.LessOrEqual(t, call[1], int64(numLines), "lines so far should not exceed the total")
+			}
+
+			require.Equal(t, [2]int64{int64(len(input)), numLines}, calls[3], "the last call should be the final result")
+		})
+	}
+}
+
+func TestCountLinesWithOptions_progress_interval(t *testing.T) {
+	t.Parallel()
+
+	recorder := new(ProgressRecorder)
+	reader := &SlowReader{delay: time.Millisecond}
+
+	// SlowReader is endless. Read 20 chunks only.
+	numLines, err := CountLinesWithOptions(&LimitedReader{reader: reader, remain: 20}, Options{
+		Progress:         recorder.Record,
+		ProgressInterval: time.Nanosecond,
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, 20, numLines)
+	require.Len(t, recorder.Calls(), 21, "it should be called on every read and once at the end")
+}
+
+func TestCountLinesWithOptions_progress_default(t *testing.T) {
+	t.Parallel()
+
+	recorder := new(ProgressRecorder)
+
+	numLines, err := CountLinesWithOptions(strings.NewReader("Hello\nWorld"), Options{
+		Progress: recorder.Record,
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, 2, numLines)
+	require.Equal(t, [][2]int64{{11, 2}}, recorder.Calls(),
+		"short input should be reported only once at the end with the default interval")
+}
+
+func Test_reporter_nil(t *testing.T) {
+	t.Parallel()
+
+	rep := newReporter(Options{})
+
+	require.Nil(t, rep, "reporter should be nil without the callback")
+	require.NotPanics(t, func() {
+		rep.addLines(1)
+		rep.addBytes(1)
+		rep.done(1)
+	}, "nil reporter should do nothing")
+}
+
+// ============================================================================
+//  Helpers
+// ============================================================================
+
+// ProgressRecorder records the arguments of the progress callback.
+type ProgressRecorder struct {
+	calls [][2]int64
+	mu    sync.Mutex
+}
+
+// Record is the progress callback. It fails if called concurrently.
+func (r *ProgressRecorder) Record(bytesRead, linesSoFar int64) {
+	if !r.mu.TryLock() {
+		panic("progress callback is called concurrently")
+	}
+
+	defer r.mu.Unlock()
+
+	r.calls = append(r.calls, [2]int64{bytesRead, linesSoFar})
+}
+
+// Calls returns the recorded arguments.
+func (r *ProgressRecorder) Calls() [][2]int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.calls
+}
+
+// LimitedReader reads up to "remain" times from the reader.
+type LimitedReader struct {
+	reader io.Reader
+	remain int
+}
+
+func (r *LimitedReader) Read(p []byte) (int, error) {
+	if r.remain <= 0 {
+		return 0, io.EOF
+	}
+
+	r.remain--
+
+	return r.reader.Read(p)
+}
@@ -0,0 +1,7 @@
+//go:build race
+
+package cl
+
+// isRaceEnabled is true if the race detector is enabled. sync.Pool drops the
+// items randomly under the race detector.
+const isRaceEnabled = true
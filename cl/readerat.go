@@ -0,0 +1,307 @@
+package cl
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+//  CountLinesReaderAt
+// ----------------------------------------------------------------------------
+
+// CountLinesReaderAt counts the number of lines in the first "size" bytes of the
+// given io.ReaderAt.
+//
+// The byte range is split into sections and each section is read and counted in
+// parallel via ReadAt (pread for *os.File). If the input is shorter than the size,
+// it counts up to the end of the input.
+func CountLinesReaderAt(inputReader io.ReaderAt, size int64) (int, error) {
+	count, err := CountLinesReaderAt64(inputReader, size)
+	if err != nil {
+		return 0, err
+	}
+
+	return toInt(count)
+}
+
+// ----------------------------------------------------------------------------
+//  CountLinesReaderAt64
+// ----------------------------------------------------------------------------
+
+// CountLinesReaderAt64 is the same as CountLinesReaderAt but returns the number
+// of lines as uint64.
+func CountLinesReaderAt64(inputReader io.ReaderAt, size int64) (uint64, error) {
+	if inputReader == nil {
+		return 0, ErrNilReader
+	}
+
+	if size < 0 {
+		return 0, errors.Errorf("invalid size: %d", size)
+	}
+
+	count, err := countReaderAt(context.Background(), inputReader, size, numWorkers(), Options{}.normalize())
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// ----------------------------------------------------------------------------
+//  countReaderAt
+// ----------------------------------------------------------------------------
+
+// countReaderAt splits the first "size" bytes of the input into "numSection"
+// sections and counts the terminators of each section in parallel.
+//
+// Each section is read with a pooled chunkSize-length buffer, so the memory usage
+// is O(numSection * chunkSize). On context cancellation, it returns the number of
+// lines counted so far along with ctx.Err().
+func countReaderAt(
+	ctx context.Context, inputReader io.ReaderAt, size int64, numSection int, opts Options,
+) (uint64, error) {
+	rep := newReporter(opts)
+
+	count, trail, err := countSections(ctx, inputReader, size, numSection, opts.Terminator, rep)
+	if err != nil {
+		return count, err
+	}
+
+	// Detect the input ends without a terminator and count up if so.
+	if opts.hasFragment(trail) {
+		count++
+	}
+
+	rep.done(count)
+
+	return count, nil
+}
+
+// countSections counts the terminators of the sections in parallel. It returns
+// the number of terminators and the last bytes of the input.
+func countSections(
+	ctx context.Context, inputReader io.ReaderAt, size int64, numSection int, term Terminator, rep *reporter,
+) (uint64, *trailer, error) {
+	// Do not split into sections smaller than a chunk
+	if maxSection := int((size + chunkSize - 1) / chunkSize); numSection > maxSection {
+		numSection = max(maxSection, 1)
+	}
+
+	ctxSections, cancels := sectionContexts(ctx, numSection)
+	defer cancels.cancelFrom(0)
+
+	var wg sync.WaitGroup
+
+	results := make([]sectionResult, numSection)
+	lenSection := size / int64(numSection)
+
+	for index := range numSection {
+		begin := int64(index) * lenSection
+		end := begin + lenSection
+
+		if index == numSection-1 {
+			end = size // the last section takes the remainder
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			results[index] = countSection(ctxSections[index], inputReader, begin, end, term, rep)
+			if results[index].err != nil {
+				cancels.cancelFrom(index + 1) // stop the following sections
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	count, trail := mergeSections(results, term)
+
+	if err := firstError(results); err != nil {
+		if ctx.Err() != nil {
+			return count, nil, ctx.Err()
+		}
+
+		return count, nil, err
+	}
+
+	return count, trail, nil
+}
+
+// cancelFuncs is the cancel functions of the sections.
+type cancelFuncs []context.CancelFunc
+
+// cancelFrom cancels the sections from the given index to the end.
+func (c cancelFuncs) cancelFrom(index int) {
+	for _, cancel := range c[index:] {
+		cancel()
+	}
+}
+
+// sectionContexts returns a child context of ctx for each section.
+//
+// Each section has its own context so that a failing section stops only the
+// sections after it. The sections before it are counted to the end to report the
+// number of lines before the failure in ReadError.
+func sectionContexts(ctx context.Context, numSection int) ([]context.Context, cancelFuncs) {
+	ctxSections := make([]context.Context, numSection)
+	cancels := make(cancelFuncs, numSection)
+
+	for index := range numSection {
+		ctxSections[index], cancels[index] = context.WithCancel(ctx)
+	}
+
+	return ctxSections, cancels
+}
+
+// mergeSections merges the results in order to correct the terminators split
+// between the sections. It also sets the number of lines before the failure to
+// the ReadError of the results.
+func mergeSections(results []sectionResult, term Terminator) (uint64, *trailer) {
+	trail := new(trailer)
+	count := uint64(0)
+
+	for _, result := range results {
+		count += result.count + term.straddle(trail.tail, result.head)
+
+		trail.merge(result.trail)
+
+		if readErr := (*ReadError)(nil); errors.As(result.err, &readErr) {
+			readErr.Lines = int64(count) //nolint:gosec // never exceeds in practice
+		}
+	}
+
+	return count, trail
+}
+
+// sectionResult is the result of countSection.
+type sectionResult struct {
+	err   error
+	head  []byte  // the first bytes of the section
+	trail trailer // the last bytes of the section
+	count uint64
+}
+
+// countSection counts the terminators between the "begin" and "end" offset of
+// the input.
+func countSection(
+	ctx context.Context, inputReader io.ReaderAt, begin, end int64, term Terminator, rep *reporter,
+) sectionResult {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	result := sectionResult{}
+
+	for offset := begin; offset < end; {
+		if err := ctx.Err(); err != nil {
+			result.err = err
+
+			return result
+		}
+
+		chunk := *buf
+		if remain := end - offset; remain < int64(len(chunk)) {
+			chunk = chunk[:remain]
+		}
+
+		numRead, err := inputReader.ReadAt(chunk, offset)
+		chunk = chunk[:numRead]
+
+		if len(result.head) < lenMaxTerminator-1 {
+			result.head = append(result.head, chunk[:min(len(chunk), lenMaxTerminator-1-len(result.head))]...)
+		}
+
+		found := term.count(chunk) + term.straddle(result.trail.tail, chunk)
+
+		result.count += found
+		result.trail.push(chunk)
+		rep.addLines(found)
+		rep.addBytes(numRead)
+
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				result.err = &ReadError{Offset: offset + int64(numRead), Err: err}
+			}
+
+			// The input is shorter than expected
+			return result
+		}
+
+		offset += int64(numRead)
+	}
+
+	return result
+}
+
+// firstError returns the first error in the results other than a context error.
+// If all the errors are context errors, it returns the first one.
+func firstError(results []sectionResult) error {
+	var ctxErr error
+
+	for _, result := range results {
+		switch {
+		case result.err == nil:
+			continue
+		case errors.Is(result.err, context.Canceled), errors.Is(result.err, context.DeadlineExceeded):
+			if ctxErr == nil {
+				ctxErr = result.err
+			}
+		default:
+			return result.err
+		}
+	}
+
+	return ctxErr
+}
+
+// ----------------------------------------------------------------------------
+//  sectionOf
+// ----------------------------------------------------------------------------
+
+// sizedReaderAt is an io.ReaderAt with a known size, such as *bytes.Reader,
+// *strings.Reader and *io.SectionReader.
+type sizedReaderAt interface {
+	io.ReaderAt
+	io.Seeker
+	Size() int64
+}
+
+// sectionOf returns the unread part of the input as a section if the input is
+// an io.ReaderAt of a known size. Otherwise it returns false.
+//
+// The seeker is returned to move the read offset to the end after counting.
+func sectionOf(inputReader io.Reader) (*io.SectionReader, io.Seeker, bool) {
+	var (
+		readerAt io.ReaderAt
+		seeker   io.Seeker
+		size     int64
+	)
+
+	switch typed := inputReader.(type) {
+	case *os.File:
+		info, err := typed.Stat()
+		// Special files (pipes, devices, procfs, etc.) do not have a reliable size
+		if err != nil || !info.Mode().IsRegular() || info.Size() == 0 {
+			return nil, nil, false
+		}
+
+		readerAt, seeker, size = typed, typed, info.Size()
+	case sizedReaderAt:
+		readerAt, seeker, size = typed, typed, typed.Size()
+	default:
+		return nil, nil, false
+	}
+
+	offset, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil || offset > size {
+		return nil, nil, false
+	}
+
+	return io.NewSectionReader(readerAt, offset, size-offset), seeker, true
+}
@@ -0,0 +1,277 @@
+package cl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/KEINOS/go-countline/cl/spec"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+//  Tests
+// ============================================================================
+
+func TestCountLinesReaderAt_golden(t *testing.T) {
+	t.Parallel()
+
+	spec.RunSpecTest(t, "CountLinesReaderAt", func(r io.Reader) (int, error) {
+		reader, ok := r.(*strings.Reader)
+		require.True(t, ok, "spec test should provide *strings.Reader")
+
+		return CountLinesReaderAt(reader, reader.Size())
+	})
+}
+
+func TestCountLinesReaderAt64_golden(t *testing.T) {
+	t.Parallel()
+
+	spec.RunSpecTest64(t, "CountLinesReaderAt64", func(r io.Reader) (uint64, error) {
+		reader, ok := r.(*strings.Reader)
+		require.True(t, ok, "spec test should provide *strings.Reader")
+
+		return CountLinesReaderAt64(reader, reader.Size())
+	})
+}
+
+func TestCountLinesReaderAt_nil_input(t *testing.T) {
+	t.Parallel()
+
+	numLines, err := CountLinesReaderAt(nil, 10)
+
+	require.Error(t, err)
+	require.Equal(t, 0, numLines, "returned number of lines should be 0 on error")
+	require.Contains(t, err.Error(), "given reader is nil")
+}
+
+func TestCountLinesReaderAt_negative_size(t *testing.T) {
+	t.Parallel()
+
+	numLines, err := CountLinesReaderAt(strings.NewReader("Hello\n"), -1)
+
+	require.Error(t, err)
+	require.Equal(t, 0, numLines, "returned number of lines should be 0 on error")
+	require.Contains(t, err.Error(), "invalid size: -1")
+}
+
+func TestCountLinesReaderAt_size_exceeds_input(t *testing.T) {
+	t.Parallel()
+
+	numLines, err := CountLinesReaderAt(strings.NewReader("Hello\nWorld"), 1024)
+
+	require.NoError(t, err, "input shorter than the size should not be an error")
+	require.Equal(t, 2, numLines)
+}
+
+func TestCountLinesReaderAt_io_read_fail(t *testing.T) {
+	t.Parallel()
+
+	numLines, err := CountLinesReaderAt(&DummyReaderAt{}, chunkSize*8)
+
+	require.Error(t, err)
+	require.Equal(t, 0, numLines, "returned number of lines should be 0 on error")
+	require.Contains(t, err.Error(), "failed to read from reader")
+	require.Contains(t, err.Error(), "forced error", "the error should contain the reason of the error")
+}
+
+func Test_countReaderAt_multiple_sections(t *testing.T) {
+	t.Parallel()
+
+	for _, numSection := range []int{1, 2, 3, 8} {
+		spec.RunSpecTest(t, fmt.Sprintf("countReaderAt_%d_sections", numSection), func(r io.Reader) (int, error) {
+			reader, ok := r.(*strings.Reader)
+			require.True(t, ok, "spec test should provide *strings.Reader")
+
+			count, err := countReaderAt(context.Background(), reader, reader.Size(), numSection, Options{}.normalize())
+
+			return int(count), err //nolint:gosec // small number of lines in spec tests
+		})
+	}
+}
+
+func Test_countReaderAt_trailing_nul_section(t *testing.T) {
+	t.Parallel()
+
+	// The last section contains only NULs
+	input := "Hello\nWorld" + strings.Repeat("\x00", chunkSize*2)
+
+	count, err := countReaderAt(context.Background(), strings.NewReader(input), int64(len(input)), 4, Options{}.normalize())
+
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), count)
+}
+
+func Test_countReaderAt_canceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	input := spec.GetStrDummyLines(chunkSize, 4)
+
+	count, err := countReaderAt(ctx, strings.NewReader(input), int64(len(input)), 4, Options{}.normalize())
+
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, uint64(0), count)
+}
+
+func Test_firstError(t *testing.T) {
+	t.Parallel()
+
+	errForced := errors.New("forced error")
+
+	require.NoError(t, firstError([]sectionResult{{}, {}}))
+
+	require.ErrorIs(t, firstError([]sectionResult{
+		{err: context.Canceled},
+		{err: errForced},
+		{err: context.DeadlineExceeded},
+	}), errForced, "non-context error should take precedence")
+
+	require.ErrorIs(t, firstError([]sectionResult{
+		{},
+		{err: context.DeadlineExceeded},
+		{err: context.Canceled},
+	}), context.DeadlineExceeded, "it should return the first context error")
+}
+
+func TestCountLines_reader_at(t *testing.T) {
+	t.Parallel()
+
+	reader := strings.NewReader("Hello\nWorld\nFoo")
+
+	// Partially read the input
+	_, err := reader.Read(make([]byte, len("Hello\n")))
+	require.NoError(t, err)
+
+	numLines, err := CountLines(reader)
+
+	require.NoError(t, err)
+	require.Equal(t, 2, numLines, "it should count the unread part only")
+	require.Zero(t, reader.Len(), "the input should be read to the end")
+}
+
+func TestCountLines_regular_file(t *testing.T) {
+	t.Parallel()
+
+	pathFile := filepath.Join(t.TempDir(), "sample.txt")
+	input := spec.GetStrDummyLines(100, chunkSize/10) // about 6 chunks
+
+	require.NoError(t, os.WriteFile(pathFile, []byte(input), 0o600))
+
+	osFile, err := os.Open(pathFile)
+	require.NoError(t, err)
+
+	defer osFile.Close()
+
+	numLines, err := CountLines(osFile)
+
+	require.NoError(t, err)
+	require.Equal(t, chunkSize/10, numLines)
+
+	offset, err := osFile.Seek(0, io.SeekCurrent)
+
+	require.NoError(t, err)
+	require.Equal(t, int64(len(input)), offset, "the read offset should be moved to the end")
+}
+
+func TestCountLines_pipe(t *testing.T) {
+	t.Parallel()
+
+	pipeReader, pipeWriter, err := os.Pipe()
+	require.NoError(t, err)
+
+	defer pipeReader.Close()
+
+	go func() {
+		_, _ = pipeWriter.WriteString("Hello\nWorld\nFoo")
+		_ = pipeWriter.Close()
+	}()
+
+	numLines, err := CountLines(pipeReader)
+
+	require.NoError(t, err, "pipes should be counted as a stream")
+	require.Equal(t, 3, numLines)
+}
+
+func TestCountLines_closed_file(t *testing.T) {
+	t.Parallel()
+
+	osFile, err := os.Open(filepath.Join("testdata", "README.md"))
+	require.NoError(t, err)
+	require.NoError(t, osFile.Close())
+
+	numLines, err := CountLines(osFile)
+
+	require.Error(t, err, "closed file should fail to read")
+	require.Equal(t, 0, numLines)
+}
+
+func TestCountLines_seek_fail(t *testing.T) {
+	t.Parallel()
+
+	t.Run("before counting", func(t *testing.T) {
+		t.Parallel()
+
+		reader := &SeekFailReader{
+			Reader:    strings.NewReader("Hello\nWorld"),
+			failAfter: 0,
+		}
+
+		numLines, err := CountLines(reader)
+
+		require.NoError(t, err, "it should fall back to the stream method")
+		require.Equal(t, 2, numLines)
+	})
+
+	t.Run("after counting", func(t *testing.T) {
+		t.Parallel()
+
+		reader := &SeekFailReader{
+			Reader:    strings.NewReader("Hello\nWorld"),
+			failAfter: 1,
+		}
+
+		numLines, err := CountLines(reader)
+
+		require.Error(t, err)
+		require.Equal(t, 0, numLines)
+		require.Contains(t, err.Error(), "failed to seek to the end of the input")
+	})
+}
+
+// ============================================================================
+//  Helpers
+// ============================================================================
+
+// DummyReaderAt is a dummy io.ReaderAt that always returns an error on ReadAt.
+type DummyReaderAt struct{}
+
+func (r *DummyReaderAt) ReadAt(_ []byte, _ int64) (int, error) {
+	return 0, errors.New("forced error")
+}
+
+// SeekFailReader is a *strings.Reader that fails to seek after "failAfter" times
+// of Seek calls.
+type SeekFailReader struct {
+	*strings.Reader
+
+	failAfter int
+	numSeek   int
+}
+
+func (r *SeekFailReader) Seek(offset int64, whence int) (int64, error) {
+	r.numSeek++
+
+	if r.numSeek > r.failAfter {
+		return 0, errors.New("forced seek error")
+	}
+
+	return r.Reader.Seek(offset, whence)
+}
@@ -0,0 +1,134 @@
+package cl
+
+import (
+	"context"
+	"hash/fnv"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// lenFingerprint is the number of bytes before the offset to fingerprint.
+const lenFingerprint = 64
+
+// ----------------------------------------------------------------------------
+//  Type: State
+// ----------------------------------------------------------------------------
+
+// State is the saved state of counting an append-only input, such as a log file.
+// Pass it to Resume to count only the appended part of the input.
+//
+// The zero value is the state of an empty input. All the fields are exported to
+// be saved, e.g. as JSON, between the runs.
+type State struct {
+	// Offset is the number of bytes counted.
+	Offset int64
+	// Count is the number of line breaks (LF) before the Offset.
+	Count uint64
+	// TailFingerprint is the FNV-1a hash of the last bytes before the Offset, up
+	// to 64 bytes. It detects the input replaced by another one.
+	TailFingerprint uint64
+	// LastWasTerminator is true if the last byte before the Offset is a line
+	// break. Trailing NULs are ignored the same way as CountLines.
+	LastWasTerminator bool
+}
+
+// Lines returns the number of lines counted the same way as CountLines. The last
+// line that does not end with a line break is counted as well.
+func (s State) Lines() uint64 {
+	if s.Offset > 0 && !s.LastWasTerminator {
+		return s.Count + 1
+	}
+
+	return s.Count
+}
+
+// ----------------------------------------------------------------------------
+//  Resume
+// ----------------------------------------------------------------------------
+
+// Resume continues counting the lines of the input from the saved state and
+// returns the new state at the given size.
+//
+// Only the bytes between the offset of the state and the size are read, plus a
+// few bytes to check the fingerprint. If the input is shorter than the offset, it
+// returns ErrTruncated. If the bytes before the offset have changed, it returns
+// ErrRotated. In both cases, start over with the zero value of State.
+func Resume(state State, inputReader io.ReaderAt, size int64) (State, error) {
+	if inputReader == nil {
+		return state, ErrNilReader
+	}
+
+	if size < 0 {
+		return state, errors.Errorf("invalid size: %d", size)
+	}
+
+	if size < state.Offset {
+		return state, ErrTruncated
+	}
+
+	if state.Offset > 0 {
+		fingerprint, err := fingerprintAt(inputReader, state.Offset)
+		if err != nil {
+			return state, err
+		}
+
+		if fingerprint != state.TailFingerprint {
+			return state, ErrRotated
+		}
+	}
+
+	appended := io.NewSectionReader(inputReader, state.Offset, size-state.Offset)
+
+	count, trail, err := countSections(context.Background(), appended, appended.Size(), numWorkers(), LF, nil)
+	if err != nil {
+		if readErr := (*ReadError)(nil); errors.As(err, &readErr) {
+			readErr.Offset += state.Offset
+			readErr.Lines += int64(state.Count) //nolint:gosec // never exceeds in practice
+		}
+
+		return state, err
+	}
+
+	fingerprint, err := fingerprintAt(inputReader, size)
+	if err != nil {
+		return state, err
+	}
+
+	next := State{
+		Offset:            size,
+		Count:             state.Count + count,
+		TailFingerprint:   fingerprint,
+		LastWasTerminator: state.LastWasTerminator,
+	}
+
+	switch {
+	case len(trail.body) > 0:
+		next.LastWasTerminator = LF.endsWith(trail.body)
+	case state.Offset == 0:
+		next.LastWasTerminator = true // nothing but NULs has no fragment
+	}
+
+	return next, nil
+}
+
+// fingerprintAt returns the hash of the last bytes before the offset.
+func fingerprintAt(inputReader io.ReaderAt, offset int64) (uint64, error) {
+	lenTail := min(offset, lenFingerprint)
+	tail := make([]byte, lenTail)
+
+	// ReaderAt may return io.EOF along with the whole bytes at the end of input
+	numRead, err := inputReader.ReadAt(tail, offset-lenTail)
+	if numRead < len(tail) {
+		if err == nil || errors.Is(err, io.EOF) {
+			return 0, errors.Wrap(io.ErrUnexpectedEOF, "input is shorter than the given size")
+		}
+
+		return 0, &ReadError{Offset: offset - lenTail + int64(numRead), Err: err}
+	}
+
+	hash := fnv.New64a()
+	_, _ = hash.Write(tail) // never fails
+
+	return hash.Sum64(), nil
+}
@@ -0,0 +1,145 @@
+package cl
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/KEINOS/go-countline/cl/spec"
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+//  Tests
+// ============================================================================
+
+func TestResume_golden(t *testing.T) {
+	t.Parallel()
+
+	spec.RunSpecTest64(t, "Resume", func(r io.Reader) (uint64, error) {
+		reader, ok := r.(*strings.Reader)
+		require.True(t, ok, "spec test should provide *strings.Reader")
+
+		state, err := Resume(State{}, reader, reader.Size())
+
+		return state.Lines(), err
+	})
+}
+
+func TestResume_appended(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range spec.DataCountLines {
+		input := test.Input
+		whole, err := Resume(State{}, strings.NewReader(input), int64(len(input)))
+		require.NoError(t, err)
+
+		// Split the input at every 7 bytes and resume from each of them as if
+		// the input was appended.
+		for split := 0; split <= len(input); split += 7 {
+			state, err := Resume(State{}, strings.NewReader(input[:split]), int64(split))
+			require.NoError(t, err)
+
+			state, err = Resume(state, strings.NewReader(input), int64(len(input)))
+			require.NoError(t, err)
+
+			require.Equal(t, whole, state, "%v: split at %d", test.Reason, split)
+			require.Equal(t, uint64(test.ExpectOut), state.Lines(), test.Reason) //nolint:gosec // never negative
+		}
+	}
+}
+
+func TestResume_trailing_nul(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		input  []string // the input appended in order
+		expect uint64
+	}{
+		{input: []string{"\x00\x00"}, expect: 0},
+		{input: []string{"\x00", "\x00"}, expect: 0},
+		{input: []string{"Hello\n", "\x00\x00"}, expect: 1},
+		{input: []string{"Hello", "\x00\x00"}, expect: 1},
+		{input: []string{"Hello\n\x00", "World"}, expect: 2},
+	} {
+		state := State{}
+		input := ""
+
+		for _, appended := range test.input {
+			input += appended
+
+			var err error
+
+			state, err = Resume(state, strings.NewReader(input), int64(len(input)))
+			require.NoError(t, err)
+		}
+
+		require.Equal(t, test.expect, state.Lines(), "input: %q", test.input)
+	}
+}
+
+func TestResume_changed_input(t *testing.T) {
+	t.Parallel()
+
+	input := strings.Repeat("Hello\n", 100)
+
+	state, err := Resume(State{}, strings.NewReader(input), int64(len(input)))
+	require.NoError(t, err)
+
+	// Truncated
+	_, err = Resume(state, strings.NewReader(input[:10]), 10)
+	require.ErrorIs(t, err, ErrTruncated)
+
+	// Rotated to another input of the same or longer size
+	rotated := strings.Repeat("World\n", 200)
+
+	_, err = Resume(state, strings.NewReader(rotated), int64(len(rotated)))
+	require.ErrorIs(t, err, ErrRotated)
+
+	// The state is still valid for the original input
+	resumed, err := Resume(state, strings.NewReader(input), int64(len(input)))
+	require.NoError(t, err)
+	require.Equal(t, state, resumed, "resuming without appended data should not change the state")
+}
+
+func TestResume_errors(t *testing.T) {
+	t.Parallel()
+
+	input := "foo\nbar\n"
+	state := State{Offset: 4, Count: 1}
+
+	for _, test := range []struct {
+		reader io.ReaderAt
+		expect string
+		state  State
+		size   int64
+	}{
+		{reader: nil, expect: "given reader is nil"},
+		{reader: strings.NewReader(input), size: -1, expect: "invalid size: -1"},
+		{reader: &DummyReaderAt{}, state: state, size: 8, expect: "failed to read from reader at offset 0"},
+		{reader: strings.NewReader(input), size: 16, expect: "input is shorter than the given size"},
+		{reader: &FailAfterReaderAt{data: []byte(input)}, size: 16, expect: "failed to read from reader at offset 8"},
+	} {
+		actual, err := Resume(test.state, test.reader, test.size)
+
+		require.ErrorContains(t, err, test.expect)
+		require.Equal(t, test.state, actual, "the given state should be returned on error")
+	}
+}
+
+func TestResume_read_error_position(t *testing.T) {
+	t.Parallel()
+
+	input := "foo\nbar\nbuzz\n"
+
+	state, err := Resume(State{}, strings.NewReader(input[:4]), 4)
+	require.NoError(t, err)
+
+	_, err = Resume(state, &FailAfterReaderAt{data: []byte(input)}, int64(len(input))+10)
+
+	var readErr *ReadError
+
+	require.ErrorAs(t, err, &readErr)
+	require.Equal(t, int64(len(input)), readErr.Offset, "offset should be from the beginning of the input")
+	require.Equal(t, int64(3), readErr.Lines, "lines should include the lines of the saved state")
+}
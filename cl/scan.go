@@ -0,0 +1,174 @@
+package cl
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+//  Type: chunkScanner
+// ----------------------------------------------------------------------------
+
+// chunkScanner scans the chunks of the input in parallel and merges the partial
+// results of each chunk in the order of the chunks.
+//
+// It is the generic version of countStream for the scans whose results depend on
+// the order of the chunks, such as the number of words split across the chunks.
+type chunkScanner[T any] struct {
+	// holdBack returns the number of bytes at the end of the chunk to carry over
+	// to the next chunk. e.g. an incomplete UTF-8 sequence.
+	holdBack func(chunk []byte) int
+	// scan returns the partial result of the chunk.
+	scan func(chunk []byte) T
+	// merge returns the result of the "left" followed by the "right".
+	merge func(left, right T) T
+}
+
+// seqChunk is a chunk of the input with its sequence number.
+type seqChunk struct {
+	buf *[]byte
+	seq int
+}
+
+// seqResult is the partial result of a chunk with its sequence number.
+type seqResult[T any] struct {
+	value T
+	seq   int
+}
+
+// scanStream reads the input sequentially into buffers and lets the given number
+// of workers scan them. The partial results are merged in order.
+//
+// On context cancellation, it returns the merged result of the chunks read so far
+// along with ctx.Err().
+func (cs chunkScanner[T]) scanStream(ctx context.Context, inputReader io.Reader, numWorker int) (T, error) {
+	var wg sync.WaitGroup
+
+	tasks := make(chan seqChunk, numWorker)
+	results := make(chan seqResult[T], numWorker)
+
+	for range numWorker {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for task := range tasks {
+				results <- seqResult[T]{seq: task.seq, value: cs.scan(*task.buf)}
+
+				putBuffer(task.buf)
+			}
+		}()
+	}
+
+	merged := make(chan T, 1)
+
+	go func() {
+		merged <- cs.mergeInOrder(results)
+	}()
+
+	err := cs.readChunks(ctx, inputReader, tasks)
+
+	// Stop the workers and wait for them to finish the queued tasks.
+	close(tasks)
+	wg.Wait()
+	close(results)
+
+	return <-merged, err
+}
+
+// readChunks reads the input into buffers and sends them to the tasks channel
+// until EOF. The bytes held back by holdBack are carried over to the next chunk.
+func (cs chunkScanner[T]) readChunks(ctx context.Context, inputReader io.Reader, tasks chan<- seqChunk) error {
+	carry := []byte{}
+	offset := int64(0)
+
+	for seq := 0; ; {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		buf := carryBuffer(carry)
+
+		numRead, err := inputReader.Read((*buf)[len(carry):]) // loading chunk into buffer
+		chunk := (*buf)[:len(carry)+numRead]
+		offset += int64(numRead)
+
+		hold := 0
+		if err == nil {
+			hold = cs.holdBack(chunk)
+		}
+
+		carry = append(carry[:0], chunk[len(chunk)-hold:]...)
+
+		if lenTask := len(chunk) - hold; lenTask > 0 {
+			*buf = chunk[:lenTask]
+			tasks <- seqChunk{buf: buf, seq: seq}
+			seq++
+		} else {
+			putBuffer(buf)
+		}
+
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return &ReadError{Offset: offset, Err: err}
+		}
+	}
+}
+
+// mergeInOrder merges the partial results in the order of the sequence numbers.
+// It returns the zero value of T if no result is given.
+func (cs chunkScanner[T]) mergeInOrder(results <-chan seqResult[T]) T {
+	var total T
+
+	pending := map[int]T{}
+	next := 0
+
+	for result := range results {
+		pending[result.seq] = result.value
+
+		for {
+			value, ok := pending[next]
+			if !ok {
+				break
+			}
+
+			delete(pending, next)
+
+			if next == 0 {
+				total = value
+			} else {
+				total = cs.merge(total, value)
+			}
+
+			next++
+		}
+	}
+
+	return total
+}
+
+// carryBuffer returns a buffer that begins with the carried over bytes. The rest
+// of the buffer is for the next read.
+//
+// If the carry is large, such as a long line, it allocates a buffer twice the
+// size of the carry so that the copy cost stays linear to the input size.
+func carryBuffer(carry []byte) *[]byte {
+	if len(carry) <= chunkSize/2 {
+		buf := getBuffer()
+		copy(*buf, carry)
+
+		return buf
+	}
+
+	buf := make([]byte, len(carry)*2) //nolint:mnd // double the size
+	copy(buf, carry)
+
+	return &buf
+}
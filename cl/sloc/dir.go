@@ -0,0 +1,212 @@
+package sloc
+
+import (
+	"io/fs"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+//  Type: LanguageResult
+// ----------------------------------------------------------------------------
+
+// LanguageResult is the sum of the Results of the files in the same language.
+type LanguageResult struct {
+	Result
+
+	// Language is the name of the language.
+	Language string
+	// Files is the number of the files in the language.
+	Files int64
+}
+
+// ----------------------------------------------------------------------------
+//  Type: FileError
+// ----------------------------------------------------------------------------
+
+// FileError is the error to count a file.
+type FileError struct {
+	// Err is the error to count the file.
+	Err error
+	// Path is the path of the file joined with the root.
+	Path string
+}
+
+// ----------------------------------------------------------------------------
+//  Type: DirResult
+// ----------------------------------------------------------------------------
+
+// DirResult is the totals of the source files in the directory tree.
+type DirResult struct {
+	// Languages are the totals per language, sorted by the number of code lines
+	// in descending order.
+	Languages []LanguageResult
+	// Failed are the files failed to count in lexical order. They are not
+	// included in the Languages.
+	Failed []FileError
+}
+
+// Err returns the first error of the files if any.
+func (r *DirResult) Err() error {
+	if len(r.Failed) == 0 {
+		return nil
+	}
+
+	return r.Failed[0].Err
+}
+
+// ----------------------------------------------------------------------------
+//  CountDir
+// ----------------------------------------------------------------------------
+
+// CountDir classifies the lines of the source files under the directory and
+// returns the totals per language, sorted by the number of code lines in
+// descending order the same as "cloc".
+//
+// The files of the unknown languages and the hidden files and directories, such
+// as ".git", are skipped. The files are counted in parallel.
+//
+// The error to count a file, such as a permission error, is stored in the
+// DirResult.Failed and does not stop the others. It returns an error only if the
+// walk itself fails.
+func CountDir(root string) (*DirResult, error) {
+	paths, err := listSources(root)
+	if err != nil {
+		return nil, err
+	}
+
+	return countFiles(paths, runtime.GOMAXPROCS(0)), nil
+}
+
+// Sum returns the total of all the languages.
+func Sum(results []LanguageResult) LanguageResult {
+	sum := LanguageResult{Language: "SUM"}
+
+	for _, result := range results {
+		sum.Files += result.Files
+		sum.Result = sum.add(result.Result)
+	}
+
+	return sum
+}
+
+// countFiles counts the files with the given number of workers and returns the
+// totals per language along with the files failed to count.
+func countFiles(paths []string, numWorkers int) *DirResult {
+	type fileResult struct {
+		err    error
+		lang   string
+		result Result
+	}
+
+	jobs := make(chan int)
+	results := make([]fileResult, len(paths))
+
+	var wg sync.WaitGroup
+
+	for range max(numWorkers, 1) {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			// Each worker writes to its own elements only
+			for index := range jobs {
+				result, lang, err := CountFile(paths[index])
+				if err != nil {
+					results[index] = fileResult{err: errors.Wrap(err, paths[index])}
+
+					continue
+				}
+
+				results[index] = fileResult{lang: lang.Name, result: result}
+			}
+		}()
+	}
+
+	for index := range paths {
+		jobs <- index
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	dirResult := &DirResult{Failed: []FileError{}}
+	totals := map[string]*LanguageResult{}
+
+	for index, fileRes := range results {
+		if fileRes.err != nil {
+			dirResult.Failed = append(dirResult.Failed, FileError{Path: paths[index], Err: fileRes.err})
+
+			continue
+		}
+
+		total, ok := totals[fileRes.lang]
+		if !ok {
+			total = &LanguageResult{Language: fileRes.lang}
+			totals[fileRes.lang] = total
+		}
+
+		total.Files++
+		total.Result = total.add(fileRes.result)
+	}
+
+	dirResult.Languages = sortResults(totals)
+
+	return dirResult
+}
+
+// listSources returns the paths of the files of the known languages under the
+// root directory.
+func listSources(root string) ([]string, error) {
+	paths := []string{}
+
+	err := filepath.WalkDir(root, func(pathFile string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if pathFile != root && strings.HasPrefix(entry.Name(), ".") {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if entry.Type().IsRegular() {
+			if _, ok := Lookup(pathFile); ok {
+				paths = append(paths, pathFile)
+			}
+		}
+
+		return nil
+	})
+
+	return paths, errors.Wrap(err, "failed to walk directory")
+}
+
+// sortResults returns the results sorted by the code lines in descending order
+// and then by the name of the language.
+func sortResults(totals map[string]*LanguageResult) []LanguageResult {
+	sorted := make([]LanguageResult, 0, len(totals))
+
+	for _, total := range totals {
+		sorted = append(sorted, *total)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Code != sorted[j].Code {
+			return sorted[i].Code > sorted[j].Code
+		}
+
+		return sorted[i].Language < sorted[j].Language
+	})
+
+	return sorted
+}
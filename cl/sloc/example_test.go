@@ -0,0 +1,41 @@
+package sloc_test
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/KEINOS/go-countline/cl/sloc"
+)
+
+func ExampleCount() {
+	const source = `package main
+
+/*
+Command hello prints "hello".
+*/
+func main() {
+	println("// not a comment") // comment
+}
+`
+
+	lang, ok := sloc.Lookup("main.go")
+	if !ok {
+		log.Fatal("unknown language")
+	}
+
+	result, err := sloc.Count(strings.NewReader(source), lang)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println("Language:", lang.Name)
+	fmt.Println("Code:", result.Code)
+	fmt.Println("Comment:", result.Comment)
+	fmt.Println("Blank:", result.Blank)
+	// Output:
+	// Language: Go
+	// Code: 4
+	// Comment: 3
+	// Blank: 1
+}
@@ -0,0 +1,186 @@
+package sloc
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// ----------------------------------------------------------------------------
+//  Type: Language
+// ----------------------------------------------------------------------------
+
+// Language is the comment and string literal rules of a programming language.
+type Language struct {
+	// Name is the name of the language to be reported. e.g. "Go".
+	Name string
+	// LineComments are the prefixes of the comments until the end of the line.
+	// e.g. "//".
+	LineComments []string
+	// BlockComments are the pairs of the beginning and the end of the block
+	// comments. e.g. {"/*", "*/"}.
+	BlockComments []Delimiter
+	// Strings are the delimiters of the string literals. The comment markers in
+	// the string literals are not comments. Longer delimiters must come first,
+	// such as `"""` before `"`.
+	Strings []Delimiter
+}
+
+// Delimiter is the pair of the beginning and the end of a block comment or a
+// string literal.
+type Delimiter struct {
+	Start string
+	End   string
+	// Escape is the escape character in the string literal, such as '\\'. Zero
+	// if the literal has no escape sequences.
+	Escape byte
+}
+
+// ----------------------------------------------------------------------------
+//  Language table
+// ----------------------------------------------------------------------------
+
+// Common sets of the rules shared between the languages.
+var (
+	cComments       = []Delimiter{{Start: "/*", End: "*/"}}
+	doubleQuoted    = Delimiter{Start: `"`, End: `"`, Escape: '\\'}
+	singleQuoted    = Delimiter{Start: "'", End: "'", Escape: '\\'}
+	rawSingleQuoted = Delimiter{Start: "'", End: "'"}
+	backQuoted      = Delimiter{Start: "`", End: "`"}
+)
+
+// languages is the table of the languages keyed by the file extension.
+//
+//nolint:gochecknoglobals // the table is read-only
+var languages = newLanguageTable()
+
+// newLanguageTable returns the table of the languages keyed by the extension.
+//
+//nolint:funlen // the table is long but simple
+func newLanguageTable() map[string]*Language {
+	table := map[string]*Language{}
+
+	for _, entry := range []struct {
+		lang       *Language
+		extensions []string
+	}{
+		{
+			lang: &Language{
+				Name:          "Go",
+				LineComments:  []string{"//"},
+				BlockComments: cComments,
+				Strings:       []Delimiter{doubleQuoted, singleQuoted, backQuoted},
+			},
+			extensions: []string{".go"},
+		},
+		{
+			lang: &Language{
+				Name:          "C",
+				LineComments:  []string{"//"},
+				BlockComments: cComments,
+				Strings:       []Delimiter{doubleQuoted, singleQuoted},
+			},
+			extensions: []string{".c", ".h"},
+		},
+		{
+			lang: &Language{
+				Name:          "C++",
+				LineComments:  []string{"//"},
+				BlockComments: cComments,
+				Strings:       []Delimiter{doubleQuoted, singleQuoted},
+			},
+			extensions: []string{".cc", ".cpp", ".cxx", ".hh", ".hpp", ".hxx"},
+		},
+		{
+			lang: &Language{
+				Name:          "C#",
+				LineComments:  []string{"//"},
+				BlockComments: cComments,
+				Strings:       []Delimiter{doubleQuoted, singleQuoted},
+			},
+			extensions: []string{".cs"},
+		},
+		{
+			lang: &Language{
+				Name:          "Java",
+				LineComments:  []string{"//"},
+				BlockComments: cComments,
+				Strings:       []Delimiter{{Start: `"""`, End: `"""`, Escape: '\\'}, doubleQuoted, singleQuoted},
+			},
+			extensions: []string{".java"},
+		},
+		{
+			lang: &Language{
+				Name:          "JavaScript",
+				LineComments:  []string{"//"},
+				BlockComments: cComments,
+				Strings:       []Delimiter{doubleQuoted, singleQuoted, {Start: "`", End: "`", Escape: '\\'}},
+			},
+			extensions: []string{".js", ".mjs", ".cjs", ".jsx"},
+		},
+		{
+			lang: &Language{
+				Name:          "TypeScript",
+				LineComments:  []string{"//"},
+				BlockComments: cComments,
+				Strings:       []Delimiter{doubleQuoted, singleQuoted, {Start: "`", End: "`", Escape: '\\'}},
+			},
+			extensions: []string{".ts", ".tsx"},
+		},
+		{
+			lang: &Language{
+				Name:         "Python",
+				LineComments: []string{"#"},
+				Strings: []Delimiter{
+					{Start: `"""`, End: `"""`, Escape: '\\'},
+					{Start: "'''", End: "'''", Escape: '\\'},
+					doubleQuoted,
+					singleQuoted,
+				},
+			},
+			extensions: []string{".py", ".pyw"},
+		},
+		{
+			lang: &Language{
+				Name:         "Shell",
+				LineComments: []string{"#"},
+				Strings:      []Delimiter{doubleQuoted, rawSingleQuoted},
+			},
+			extensions: []string{".sh", ".bash", ".zsh", ".ksh"},
+		},
+		{
+			lang: &Language{
+				Name:         "YAML",
+				LineComments: []string{"#"},
+				// The single quote is not a string literal since it appears in plain
+				// scalars, such as "don't", and it would never be closed.
+				Strings: []Delimiter{doubleQuoted},
+			},
+			extensions: []string{".yaml", ".yml"},
+		},
+		{
+			lang: &Language{
+				Name:          "SQL",
+				LineComments:  []string{"--"},
+				BlockComments: cComments,
+				// The quote in a string literal is escaped by doubling it ('') which is
+				// scanned as two adjacent literals.
+				Strings: []Delimiter{rawSingleQuoted, {Start: `"`, End: `"`}},
+			},
+			extensions: []string{".sql"},
+		},
+	} {
+		for _, ext := range entry.extensions {
+			table[ext] = entry.lang
+		}
+	}
+
+	return table
+}
+
+// Lookup returns the language of the file by its extension. The extension is
+// case-insensitive. It returns false if the language is unknown.
+func Lookup(pathFile string) (*Language, bool) {
+	lang, ok := languages[strings.ToLower(filepath.Ext(pathFile))]
+
+	return lang, ok
+}
@@ -0,0 +1,235 @@
+/*
+Package sloc counts the source lines of code. Each line of a source file is
+classified into code, comment and blank lines by the comment rules of the
+language, similar to the "cloc" command.
+*/
+package sloc
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/KEINOS/go-countline/cl"
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+//  Type: Result
+// ----------------------------------------------------------------------------
+
+// Result is the number of lines of each kind.
+type Result struct {
+	// Code is the number of lines with code. A line with both code and comment
+	// is a code line.
+	Code int64
+	// Comment is the number of lines with comments only.
+	Comment int64
+	// Blank is the number of lines with white spaces only.
+	Blank int64
+}
+
+// Total returns the number of lines.
+func (r Result) Total() int64 {
+	return r.Code + r.Comment + r.Blank
+}
+
+// add returns the sum of the two.
+func (r Result) add(other Result) Result {
+	return Result{
+		Code:    r.Code + other.Code,
+		Comment: r.Comment + other.Comment,
+		Blank:   r.Blank + other.Blank,
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  Count
+// ----------------------------------------------------------------------------
+
+// Count classifies the lines of the input by the rules of the language.
+func Count(inputReader io.Reader, lang *Language) (Result, error) {
+	if inputReader == nil {
+		return Result{}, cl.ErrNilReader
+	}
+
+	if lang == nil {
+		return Result{}, errors.New("given language is nil")
+	}
+
+	reader := bufio.NewReader(inputReader)
+	state := scanState{lang: lang}
+	result := Result{}
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			result = result.add(state.classify(line))
+		}
+
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return result, nil
+			}
+
+			return Result{}, errors.Wrap(err, "failed to read from reader")
+		}
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  CountFile
+// ----------------------------------------------------------------------------
+
+// CountFile classifies the lines of the file by the language of its extension.
+// It also returns the language of the file. It is an error if the language of the
+// file is unknown.
+func CountFile(pathFile string) (Result, *Language, error) {
+	lang, ok := Lookup(pathFile)
+	if !ok {
+		return Result{}, nil, errors.Errorf("unknown language: %s", pathFile)
+	}
+
+	osFile, err := os.Open(filepath.Clean(pathFile))
+	if err != nil {
+		return Result{}, nil, errors.Wrap(err, "failed to open file")
+	}
+
+	defer osFile.Close()
+
+	result, err := Count(osFile, lang)
+
+	return result, lang, err
+}
+
+// ----------------------------------------------------------------------------
+//  Type: scanState
+// ----------------------------------------------------------------------------
+
+// scanState is the state of scanning a file kept between the lines, such as in a
+// block comment or a multi-line string literal.
+type scanState struct {
+	lang    *Language
+	block   *Delimiter // the block comment being scanned. nil if not in it
+	literal *Delimiter // the string literal being scanned. nil if not in it
+}
+
+// classify returns the kind of the line as a Result of one line. A blank line in
+// a block comment is a blank line the same as "cloc".
+func (s *scanState) classify(line []byte) Result {
+	line = bytes.TrimRight(line, "\r\n")
+
+	if len(bytes.TrimSpace(line)) == 0 {
+		if s.literal != nil {
+			return Result{Code: 1} // blank line in a string literal is a part of the code
+		}
+
+		return Result{Blank: 1}
+	}
+
+	hasCode := false
+
+	for pos := 0; pos < len(line); {
+		switch {
+		case s.block != nil:
+			pos = s.skipBlock(line, pos)
+		case s.literal != nil:
+			hasCode = true
+			pos = s.skipLiteral(line, pos)
+		case isSpace(line[pos]):
+			pos++
+		case s.hasPrefix(line[pos:], s.lang.LineComments):
+			pos = len(line)
+		default:
+			hasCode = s.begin(line, &pos) || hasCode
+		}
+	}
+
+	if hasCode {
+		return Result{Code: 1}
+	}
+
+	return Result{Comment: 1} // a non-blank line without code
+}
+
+// begin begins a block comment or a string literal at the position, or skips a
+// character of code. It returns true if it is code.
+func (s *scanState) begin(line []byte, pos *int) bool {
+	for i := range s.lang.BlockComments {
+		if delim := &s.lang.BlockComments[i]; bytes.HasPrefix(line[*pos:], []byte(delim.Start)) {
+			s.block = delim
+			*pos += len(delim.Start)
+
+			return false
+		}
+	}
+
+	for i := range s.lang.Strings {
+		if delim := &s.lang.Strings[i]; bytes.HasPrefix(line[*pos:], []byte(delim.Start)) {
+			s.literal = delim
+			*pos += len(delim.Start)
+
+			return true
+		}
+	}
+
+	*pos++
+
+	return true
+}
+
+// skipBlock skips to the end of the block comment and returns the position after
+// it. It returns the end of the line if the comment continues.
+func (s *scanState) skipBlock(line []byte, pos int) int {
+	end := bytes.Index(line[pos:], []byte(s.block.End))
+	if end < 0 {
+		return len(line)
+	}
+
+	pos += end + len(s.block.End)
+	s.block = nil
+
+	return pos
+}
+
+// skipLiteral skips to the end of the string literal and returns the position
+// after it. It returns the end of the line if the literal continues.
+func (s *scanState) skipLiteral(line []byte, pos int) int {
+	for pos < len(line) {
+		if s.literal.Escape != 0 && line[pos] == s.literal.Escape {
+			pos += 2 // skip the escaped character
+
+			continue
+		}
+
+		if bytes.HasPrefix(line[pos:], []byte(s.literal.End)) {
+			pos += len(s.literal.End)
+			s.literal = nil
+
+			return pos
+		}
+
+		pos++
+	}
+
+	return len(line)
+}
+
+// hasPrefix returns true if the line begins with one of the prefixes.
+func (s *scanState) hasPrefix(line []byte, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(string(line), prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isSpace returns true if the byte is an ASCII white space.
+func isSpace(char byte) bool {
+	return char == ' ' || ('\t' <= char && char <= '\r')
+}
@@ -0,0 +1,276 @@
+package sloc
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/KEINOS/go-countline/cl"
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+//  Tests
+// ============================================================================
+
+func TestCount(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		name   string
+		ext    string
+		input  string
+		expect Result
+	}{
+		{name: "empty", ext: ".go", input: "", expect: Result{}},
+		{name: "blank lines", ext: ".go", input: "\n \t\n\r\n", expect: Result{Blank: 3}},
+		{name: "no trailing line break", ext: ".go", input: "package main", expect: Result{Code: 1}},
+		{
+			name:   "go line comments",
+			ext:    ".go",
+			input:  "// Package main\npackage main // trailing\n\n\t// indented\n",
+			expect: Result{Code: 1, Comment: 2, Blank: 1},
+		},
+		{
+			// The blank line in the block comment is a blank line the same as "cloc"
+			name:   "go block comment across lines",
+			ext:    ".go",
+			input:  "/*\n  doc\n\n*/\nfunc main() {} /* a */\n/* b */ x := 1\n/* c */ /* d */\n",
+			expect: Result{Code: 2, Comment: 4, Blank: 1},
+		},
+		{
+			name:   "go block comment ends with code",
+			ext:    ".go",
+			input:  "/* a\n*/ x := 1\n",
+			expect: Result{Code: 1, Comment: 1},
+		},
+		{
+			name:   "go strings look like comments",
+			ext:    ".go",
+			input:  "s := \"// not comment\"\nr := '\"'\nu := \"\\\" /* \"\n",
+			expect: Result{Code: 3},
+		},
+		{
+			name:   "go multi-line raw string",
+			ext:    ".go",
+			input:  "s := `\n// not comment\n\n/* nor this\n`\n// comment\n",
+			expect: Result{Code: 5, Comment: 1},
+		},
+		{
+			name:   "c with CRLF",
+			ext:    ".c",
+			input:  "#include <stdio.h>\r\n/* comment */\r\n\r\nint main() { return 0; }\r\n",
+			expect: Result{Code: 2, Comment: 1, Blank: 1},
+		},
+		{
+			name:   "python docstring is code",
+			ext:    ".py",
+			input:  "# comment\ndef f():\n    \"\"\"doc\n    # not comment\n    \"\"\"\n    return '#'\n",
+			expect: Result{Code: 5, Comment: 1},
+		},
+		{
+			name:   "shell",
+			ext:    ".sh",
+			input:  "#!/bin/sh\n\necho '\\' # comment\necho \"#\" # comment\n",
+			expect: Result{Code: 2, Comment: 1, Blank: 1},
+		},
+		{
+			name:   "yaml",
+			ext:    ".yml",
+			input:  "# comment\nkey: don't # comment\nurl: \"http://example.com/#top\"\n",
+			expect: Result{Code: 2, Comment: 1},
+		},
+		{
+			name:   "sql",
+			ext:    ".sql",
+			input:  "-- comment\nSELECT '--', 'it''s' FROM t; -- comment\n/*\n*/\n",
+			expect: Result{Code: 1, Comment: 3},
+		},
+	} {
+		lang, ok := Lookup("file" + test.ext)
+		require.True(t, ok, "language of %s should be known", test.ext)
+
+		for name, reader := range map[string]io.Reader{
+			"whole":    strings.NewReader(test.input),
+			"one byte": iotest.OneByteReader(strings.NewReader(test.input)),
+		} {
+			actual, err := Count(reader, lang)
+
+			require.NoError(t, err, test.name)
+			require.Equal(t, test.expect, actual, "test: %s, reader: %s", test.name, name)
+		}
+	}
+}
+
+func TestCount_errors(t *testing.T) {
+	t.Parallel()
+
+	lang, ok := Lookup("main.go")
+	require.True(t, ok)
+
+	_, err := Count(nil, lang)
+	require.ErrorIs(t, err, cl.ErrNilReader)
+
+	_, err = Count(strings.NewReader(""), nil)
+	require.ErrorContains(t, err, "given language is nil")
+
+	result, err := Count(iotest.TimeoutReader(strings.NewReader("package main\n")), lang)
+	require.ErrorIs(t, err, iotest.ErrTimeout)
+	require.Equal(t, Result{}, result)
+}
+
+func TestLookup(t *testing.T) {
+	t.Parallel()
+
+	for pathFile, expect := range map[string]string{
+		"main.go":          "Go",
+		"dir/MAIN.GO":      "Go",
+		"foo.h":            "C",
+		"foo.hpp":          "C++",
+		"Main.java":        "Java",
+		"index.tsx":        "TypeScript",
+		"setup.py":         "Python",
+		"build.bash":       "Shell",
+		"compose.yaml":     "YAML",
+		"schema.sql":       "SQL",
+		"program.cs":       "C#",
+		"module.mjs":       "JavaScript",
+		"dir.go/README.md": "",
+		"Makefile":         "",
+	} {
+		lang, ok := Lookup(pathFile)
+
+		if expect == "" {
+			require.False(t, ok, pathFile)
+			require.Nil(t, lang, pathFile)
+
+			continue
+		}
+
+		require.True(t, ok, pathFile)
+		require.Equal(t, expect, lang.Name, pathFile)
+	}
+}
+
+func TestCountFile(t *testing.T) {
+	t.Parallel()
+
+	dirTemp := t.TempDir()
+
+	pathFile := writeFile(t, dirTemp, "main.go", "package main\n\n// main\nfunc main() {}\n")
+
+	result, lang, err := CountFile(pathFile)
+
+	require.NoError(t, err)
+	require.Equal(t, "Go", lang.Name)
+	require.Equal(t, Result{Code: 2, Comment: 1, Blank: 1}, result)
+	require.Equal(t, int64(4), result.Total())
+
+	_, _, err = CountFile(writeFile(t, dirTemp, "README.md", "# title\n"))
+	require.ErrorContains(t, err, "unknown language")
+
+	_, _, err = CountFile(filepath.Join(dirTemp, "missing.go"))
+	require.ErrorContains(t, err, "failed to open file")
+}
+
+func TestCountDir(t *testing.T) {
+	t.Parallel()
+
+	dirTemp := t.TempDir()
+
+	writeFile(t, dirTemp, "main.go", "package main\n\n// main\nfunc main() {}\n")
+	writeFile(t, dirTemp, "sub/util.go", "package sub\n")
+	writeFile(t, dirTemp, "sub/run.sh", "#!/bin/sh\necho\n")
+	writeFile(t, dirTemp, "sub/README.md", "# README\n")
+	writeFile(t, dirTemp, "query.sql", "SELECT 1;\nSELECT 2;\n")
+	writeFile(t, dirTemp, ".git/hooks/pre-commit.sh", "echo\n")
+	writeFile(t, dirTemp, ".hidden.go", "package hidden\n")
+
+	result, err := CountDir(dirTemp)
+	require.NoError(t, err)
+	require.NoError(t, result.Err())
+	require.Empty(t, result.Failed)
+
+	expect := []LanguageResult{
+		{Language: "Go", Files: 2, Result: Result{Code: 3, Comment: 1, Blank: 1}},
+		{Language: "SQL", Files: 1, Result: Result{Code: 2}},
+		{Language: "Shell", Files: 1, Result: Result{Code: 1, Comment: 1}},
+	}
+	require.Equal(t, expect, result.Languages)
+
+	require.Equal(t, LanguageResult{Language: "SUM", Files: 4, Result: Result{Code: 6, Comment: 2, Blank: 1}},
+		Sum(result.Languages))
+}
+
+func TestCountDir_empty(t *testing.T) {
+	t.Parallel()
+
+	result, err := CountDir(t.TempDir())
+
+	require.NoError(t, err)
+	require.Empty(t, result.Languages)
+	require.Equal(t, LanguageResult{Language: "SUM"}, Sum(result.Languages))
+}
+
+func TestCountDir_errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := CountDir(filepath.Join(t.TempDir(), "missing"))
+	require.ErrorContains(t, err, "failed to walk directory")
+}
+
+// The files failed to count, such as the ones removed while walking, must not
+// discard the totals of the others.
+func Test_countFiles_error(t *testing.T) {
+	t.Parallel()
+
+	dirTemp := t.TempDir()
+	paths := []string{
+		writeFile(t, dirTemp, "main.go", "package main\n"),
+		filepath.Join(dirTemp, "missing.go"),
+		filepath.Join(dirTemp, "missing.sh"),
+	}
+
+	result := countFiles(paths, 2)
+
+	require.Equal(t, []LanguageResult{{Language: "Go", Files: 1, Result: Result{Code: 1}}}, result.Languages)
+	require.Len(t, result.Failed, 2)
+	require.Equal(t, paths[1], result.Failed[0].Path, "failed files should be in the order of the paths")
+	require.Equal(t, paths[2], result.Failed[1].Path)
+	require.ErrorContains(t, result.Failed[0].Err, "failed to open file")
+	require.ErrorContains(t, result.Err(), paths[1], "the first error should be returned")
+}
+
+func Test_sortResults(t *testing.T) {
+	t.Parallel()
+
+	sorted := sortResults(map[string]*LanguageResult{
+		"Go":    {Language: "Go", Result: Result{Code: 1}},
+		"C":     {Language: "C", Result: Result{Code: 1}},
+		"Shell": {Language: "Shell", Result: Result{Code: 2}},
+	})
+
+	require.Len(t, sorted, 3)
+	require.Equal(t, "Shell", sorted[0].Language, "more code lines should come first")
+	require.Equal(t, "C", sorted[1].Language, "same code lines should be sorted by name")
+	require.Equal(t, "Go", sorted[2].Language)
+}
+
+// ============================================================================
+//  Helpers
+// ============================================================================
+
+// writeFile writes the data to the file under the directory and returns the path.
+func writeFile(t *testing.T, dir, name, data string) string {
+	t.Helper()
+
+	pathFile := filepath.Join(dir, filepath.FromSlash(name))
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(pathFile), 0o700))
+	require.NoError(t, os.WriteFile(pathFile, []byte(data), 0o600))
+
+	return pathFile
+}
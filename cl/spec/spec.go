@@ -87,6 +87,230 @@ var DataCountLines = []struct {
 		Input:     GetStrDummyLines(bufio.MaxScanTokenSize*2, 2),
 		ExpectOut: 2,
 	},
+	{
+		Reason:    "'Hello\\r\\nWorld\\r\\n<EOF>' --> CRLF line breaks should be counted by its LF",
+		Input:     "Hello\r\nWorld\r\n",
+		ExpectOut: 2,
+	},
+	{
+		Reason:    "'Hello\\rWorld\\r<EOF>' --> CR is not a line break and should be one",
+		Input:     "Hello\rWorld\r",
+		ExpectOut: 1,
+	},
+	{
+		Reason:    "'x^2 + y\\nfoo\\n<EOF>' --> plain text like a zlib header should be two",
+		Input:     "x^2 + y\nfoo\n",
+		ExpectOut: 2,
+	},
+	{
+		Reason:    "'BZh1 is not bzip2\\nfoo<EOF>' --> plain text like a bzip2 header should be two",
+		Input:     "BZh1 is not bzip2\nfoo",
+		ExpectOut: 2,
+	},
+}
+
+// DataCountNewlines is the data provider for counting the line breaks only, the
+// same as "wc -l" command. The inputs are the same as DataCountLines but the last
+// line without a line break is not counted.
+//
+//nolint:mnd // numbers of ExpectOut are not magic numbers and let DataCountNewlines be global.
+var DataCountNewlines = []struct {
+	Reason    string // Reason on failure
+	Input     string // Input data
+	ExpectOut int    // Expected output
+}{
+	{
+		Reason:    "'<EOF>' --> empty file should be zero",
+		Input:     "",
+		ExpectOut: 0,
+	},
+	{
+		Reason:    "'Hello<EOF>' --> single line without line break should be zero",
+		Input:     "Hello",
+		ExpectOut: 0,
+	},
+	{
+		Reason:    "'Hello\\n<EOF>' --> single line with line break should be one",
+		Input:     "Hello\n",
+		ExpectOut: 1,
+	},
+	{
+		Reason:    "'\\n<EOF>' --> single line break should be one",
+		Input:     "\n",
+		ExpectOut: 1,
+	},
+	{
+		Reason:    "'\\n\\n<EOF>' --> two line breaks should be two",
+		Input:     "\n\n",
+		ExpectOut: 2,
+	},
+	{
+		Reason:    "'\\nHello<EOF>' --> one line break and one line without line break should be one",
+		Input:     "\nHello",
+		ExpectOut: 1,
+	},
+	{
+		Reason:    "'\\nHello\\n<EOF>' --> one line break and one line with line break should be two",
+		Input:     "\nHello\n",
+		ExpectOut: 2,
+	},
+	{
+		Reason:    "'\\n\\nHello<EOF>' --> two line breaks and one line without line break should be two",
+		Input:     "\n\nHello",
+		ExpectOut: 2,
+	},
+	{
+		Reason:    "'\\n\\nHello\\n<EOF>' --> two line breaks and one line with line break should be three",
+		Input:     "\n\nHello\n",
+		ExpectOut: 3,
+	},
+	{
+		Reason:    "'<large line>\\n<EOF>' --> long string with a line break should be one",
+		Input:     GetStrDummyLines(bufio.MaxScanTokenSize*2, 1),
+		ExpectOut: 1,
+	},
+	{
+		Reason:    "'<large line>\\n<large line><EOF>' --> long string with a line break and a fragment should be one",
+		Input:     GetStrDummyLines(bufio.MaxScanTokenSize*2, 1) + strings.Repeat("a", bufio.MaxScanTokenSize*2),
+		ExpectOut: 1,
+	},
+	{
+		Reason:    "'Hello\\r\\nWorld\\r\\n<EOF>' --> CRLF line breaks should be counted by its LF",
+		Input:     "Hello\r\nWorld\r\n",
+		ExpectOut: 2,
+	},
+	{
+		Reason:    "'Hello\\rWorld\\r<EOF>' --> CR is not a line break and should be zero",
+		Input:     "Hello\rWorld\r",
+		ExpectOut: 0,
+	},
+	{
+		Reason:    "'x^2 + y\\nfoo\\n<EOF>' --> plain text like a zlib header should be two",
+		Input:     "x^2 + y\nfoo\n",
+		ExpectOut: 2,
+	},
+	{
+		Reason:    "'BZh1 is not bzip2\\nfoo<EOF>' --> plain text like a bzip2 header should be one",
+		Input:     "BZh1 is not bzip2\nfoo",
+		ExpectOut: 1,
+	},
+}
+
+// DataCountLinesTerminator is the data provider to check if the specifications
+// of counting lines with the given set of line terminators are covered.
+//
+// "Terminator" is the names of the terminators joined with "|". Such as "LF",
+// "CRLF", "CR", "Unicode" and "LF|CRLF|CR".
+//
+//nolint:mnd // numbers of ExpectOut are not magic numbers and let DataCountLinesTerminator be global.
+var DataCountLinesTerminator = []struct {
+	Reason     string // Reason on failure
+	Terminator string // Names of the terminators to count
+	Input      string // Input data
+	ExpectOut  int    // Expected output
+}{
+	{
+		Reason:     "'Hello\\r\\nWorld\\r\\n<EOF>' --> LF should count the LF of CRLF",
+		Terminator: "LF",
+		Input:      "Hello\r\nWorld\r\n",
+		ExpectOut:  2,
+	},
+	{
+		Reason:     "'Hello\\r\\nWorld\\r\\n<EOF>' --> CRLF should count the pairs",
+		Terminator: "CRLF",
+		Input:      "Hello\r\nWorld\r\n",
+		ExpectOut:  2,
+	},
+	{
+		Reason:     "'Hello\\r\\nWorld<EOF>' --> CRLF should count the last line without line break",
+		Terminator: "CRLF",
+		Input:      "Hello\r\nWorld",
+		ExpectOut:  2,
+	},
+	{
+		Reason:     "'Hello\\nWorld\\n<EOF>' --> CRLF should not count a single LF",
+		Terminator: "CRLF",
+		Input:      "Hello\nWorld\n",
+		ExpectOut:  1,
+	},
+	{
+		Reason:     "'Hello\\rWorld\\r<EOF>' --> CRLF should not count a single CR",
+		Terminator: "CRLF",
+		Input:      "Hello\rWorld\r",
+		ExpectOut:  1,
+	},
+	{
+		Reason:     "'Hello\\rWorld\\r<EOF>' --> CR should count the CRs",
+		Terminator: "CR",
+		Input:      "Hello\rWorld\r",
+		ExpectOut:  2,
+	},
+	{
+		Reason:     "'Hello\\r\\nWorld\\r\\n<EOF>' --> CR should count the LF after the last CR as a line",
+		Terminator: "CR",
+		Input:      "Hello\r\nWorld\r\n",
+		ExpectOut:  3,
+	},
+	{
+		Reason:     "'Hello\\r\\nWorld\\nFoo\\rBar<EOF>' --> mixed line breaks should be counted as four",
+		Terminator: "LF|CRLF|CR",
+		Input:      "Hello\r\nWorld\nFoo\rBar",
+		ExpectOut:  4,
+	},
+	{
+		Reason:     "'\\r\\r\\n\\n\\r<EOF>' --> CRLF should be counted as one among CRs and LFs",
+		Terminator: "LF|CRLF|CR",
+		Input:      "\r\r\n\n\r",
+		ExpectOut:  4,
+	},
+	{
+		Reason:     "'\\r\\n<EOF>' --> LF and CR without CRLF should count CRLF as two",
+		Terminator: "LF|CR",
+		Input:      "\r\n",
+		ExpectOut:  2,
+	},
+	{
+		Reason:     "'Hello<NEL>World<LS>Foo<PS><EOF>' --> Unicode should count NEL, LS and PS",
+		Terminator: "Unicode",
+		Input:      "Hello\u0085World\u2028Foo\u2029",
+		ExpectOut:  3,
+	},
+	{
+		Reason:     "'Hello\\nWorld<EOF>' --> Unicode should not count LF",
+		Terminator: "Unicode",
+		Input:      "Hello\nWorld",
+		ExpectOut:  1,
+	},
+	{
+		Reason:     "'Hello\\nWorld<LS>Foo<EOF>' --> LF and Unicode should count both",
+		Terminator: "LF|Unicode",
+		Input:      "Hello\nWorld\u2028Foo",
+		ExpectOut:  3,
+	},
+	{
+		Reason:     "'<large line>\\r|\\nFoo<EOF>' --> CRLF split at 64KiB should be counted once",
+		Terminator: "CRLF",
+		Input:      strings.Repeat("a", bufio.MaxScanTokenSize-1) + "\r\nFoo",
+		ExpectOut:  2,
+	},
+	{
+		Reason:     "'<large line>\\r|\\nFoo<EOF>' --> CRLF split at 64KiB should be counted once among CRs and LFs",
+		Terminator: "LF|CRLF|CR",
+		Input:      strings.Repeat("a", bufio.MaxScanTokenSize-1) + "\r\nFoo",
+		ExpectOut:  2,
+	},
+	{
+		Reason:     "'<large line><L|S>Foo<EOF>' --> LS split at 64KiB should be counted once",
+		Terminator: "Unicode",
+		Input:      strings.Repeat("a", bufio.MaxScanTokenSize-1) + "\u2028Foo",
+		ExpectOut:  2,
+	},
+	{
+		Reason:     "'<large line><LS|>Foo<EOF>' --> LS split at 64KiB should be counted once",
+		Terminator: "Unicode",
+		Input:      strings.Repeat("a", bufio.MaxScanTokenSize-2) + "\u2028Foo",
+		ExpectOut:  2,
+	},
 }
 
 // ============================================================================
@@ -104,43 +328,117 @@ var DataCountLines = []struct {
 func RunSpecTest(t *testing.T, nameFn string, fn func(io.Reader) (int, error)) {
 	t.Helper()
 
-	const threshold = 1024 // Max size of input data to begin cropping
-
 	for index, test := range DataCountLines {
 		testNum := fmt.Sprintf("test #%v", index)
 
 		t.Run(testNum, func(t *testing.T) {
-			logInput := test.Input
+			runSpec(t, nameFn, testNum, test.Reason, test.Input, test.ExpectOut, fn)
+		})
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  RunSpecTest64
+// ----------------------------------------------------------------------------
+
+// RunSpecTest64 is the same as RunSpecTest but for the functions that return the
+// number of lines as uint64, such as CountLines64.
+//
+//nolint:varnamelen // fn is short for the scope of its usage but leave it as is.
+func RunSpecTest64(t *testing.T, nameFn string, fn func(io.Reader) (uint64, error)) {
+	t.Helper()
 
-			// Crop the input to make it readable
-			if len(test.Input) > threshold {
-				logInput = fmt.Sprintf("%v ... %v", test.Input[:64], test.Input[len(test.Input)-64:])
-			}
+	RunSpecTest(t, nameFn, func(r io.Reader) (int, error) {
+		count, err := fn(r)
 
-			t.Logf("Input : %#v", logInput)
-			t.Log("Input len:", len(test.Input))
+		return int(count), err //nolint:gosec // expected numbers of the specs are small
+	})
+}
 
-			ioReader := strings.NewReader(test.Input)
+// ----------------------------------------------------------------------------
+//  RunSpecTestNewlines
+// ----------------------------------------------------------------------------
 
-			msgReason := fmt.Sprintf("%v %v: %v", nameFn, testNum, test.Reason)
-			expect := test.ExpectOut
+// RunSpecTestNewlines is a helper function to run the specifcations of counting
+// the line breaks only, the same as "wc -l" command.
+//
+//nolint:varnamelen // fn is short for the scope of its usage but leave it as is.
+func RunSpecTestNewlines(t *testing.T, nameFn string, fn func(io.Reader) (int, error)) {
+	t.Helper()
 
-			// Run the target function. Capture the STDOUT and STDERR as well.
-			out := capturer.CaptureOutput(func() {
-				actual, err := fn(ioReader)
+	for index, test := range DataCountNewlines {
+		testNum := fmt.Sprintf("test #%v", index)
 
-				require.NoError(t, err, "golden case should not return error")
-				assert.Equal(t, expect, actual, msgReason)
-			})
+		t.Run(testNum, func(t *testing.T) {
+			runSpec(t, nameFn, testNum, test.Reason, test.Input, test.ExpectOut, fn)
+		})
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  RunSpecTestTerminator
+// ----------------------------------------------------------------------------
+
+// RunSpecTestTerminator is a helper function to run the specifcations of counting
+// lines with the given set of line terminators.
+//
+// fn receives the names of the terminators joined with "|". e.g. "LF|CRLF".
+//
+//nolint:varnamelen // fn is short for the scope of its usage but leave it as is.
+func RunSpecTestTerminator(t *testing.T, nameFn string, fn func(terminator string, r io.Reader) (int, error)) {
+	t.Helper()
 
-			assert.Emptyf(t, out,
-				"%v %v: it should not output to STDOUT/STDERR on success.\nOut: %v",
-				nameFn, testNum, out,
-			)
+	for index, test := range DataCountLinesTerminator {
+		testNum := fmt.Sprintf("test #%v (%v)", index, test.Terminator)
+
+		t.Run(testNum, func(t *testing.T) {
+			runSpec(t, nameFn, testNum, test.Reason, test.Input, test.ExpectOut, func(r io.Reader) (int, error) {
+				return fn(test.Terminator, r)
+			})
 		})
 	}
 }
 
+// ----------------------------------------------------------------------------
+//  runSpec
+// ----------------------------------------------------------------------------
+
+//nolint:varnamelen // fn is short for the scope of its usage but leave it as is.
+func runSpec(
+	t *testing.T, nameFn, testNum, reason, input string, expect int, fn func(io.Reader) (int, error),
+) {
+	t.Helper()
+
+	const threshold = 1024 // Max size of input data to begin cropping
+
+	logInput := input
+
+	// Crop the input to make it readable
+	if len(input) > threshold {
+		logInput = fmt.Sprintf("%v ... %v", input[:64], input[len(input)-64:])
+	}
+
+	t.Logf("Input : %#v", logInput)
+	t.Log("Input len:", len(input))
+
+	ioReader := strings.NewReader(input)
+
+	msgReason := fmt.Sprintf("%v %v: %v", nameFn, testNum, reason)
+
+	// Run the target function. Capture the STDOUT and STDERR as well.
+	out := capturer.CaptureOutput(func() {
+		actual, err := fn(ioReader)
+
+		require.NoError(t, err, "golden case should not return error")
+		assert.Equal(t, expect, actual, msgReason)
+	})
+
+	assert.Emptyf(t, out,
+		"%v %v: it should not output to STDOUT/STDERR on success.\nOut: %v",
+		nameFn, testNum, out,
+	)
+}
+
 // ----------------------------------------------------------------------------
 //  GetStrDummyLines
 // ----------------------------------------------------------------------------
@@ -3,6 +3,7 @@ package spec
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"strings"
 	"testing"
 
@@ -58,6 +59,14 @@ func TestRunSpecTest(t *testing.T) {
 	})
 }
 
+func TestRunSpecTest64(t *testing.T) {
+	t.Parallel()
+
+	require.NotPanics(t, func() {
+		RunSpecTest64(t, "CountLines64", cl.CountLines64)
+	})
+}
+
 func Test_genOneLine(t *testing.T) {
 	t.Parallel()
 
@@ -107,3 +116,31 @@ func Test_genOneLine(t *testing.T) {
 		assert.Equal(t, dataLine[len(dataLine)-1], test.expectLast, "last char did not match. %s", reason)
 	}
 }
+
+func TestRunSpecTestNewlines(t *testing.T) {
+	t.Parallel()
+
+	require.NotPanics(t, func() {
+		RunSpecTestNewlines(t, "CountNewlines", cl.CountNewlines)
+	})
+}
+
+func TestRunSpecTestTerminator(t *testing.T) {
+	t.Parallel()
+
+	// Map the names of the terminators to the values
+	terminators := map[string]cl.Terminator{}
+
+	for term := cl.LF; term <= cl.LF|cl.CRLF|cl.CR|cl.Unicode; term++ {
+		terminators[term.String()] = term
+	}
+
+	require.NotPanics(t, func() {
+		RunSpecTestTerminator(t, "CountLinesWithOptions", func(terminator string, r io.Reader) (int, error) {
+			term, ok := terminators[terminator]
+			require.True(t, ok, "unknown terminator: %v", terminator)
+
+			return cl.CountLinesWithOptions(r, cl.Options{Terminator: term})
+		})
+	})
+}
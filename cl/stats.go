@@ -0,0 +1,337 @@
+package cl
+
+import (
+	"context"
+	"io"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/width"
+)
+
+// tabWidth is the interval of the tab stops to measure the display width.
+const tabWidth = 8
+
+// ----------------------------------------------------------------------------
+//  Type: Result
+// ----------------------------------------------------------------------------
+
+// Result is the statistics of the input, similar to the output of "wc -lcmwL".
+type Result struct {
+	// Lines is the number of lines. Unlike "wc -l", the last line that does not
+	// end with a line break is counted as well, the same as CountLines.
+	Lines int64
+	// Bytes is the number of bytes (wc -c).
+	Bytes int64
+	// Runes is the number of UTF-8 characters (wc -m). Each byte of an invalid
+	// UTF-8 sequence is counted as one character.
+	Runes int64
+	// Words is the number of words separated by white spaces (wc -w). White
+	// spaces are the same as GNU "wc -w" in a UTF-8 locale. These are the
+	// characters of unicode.IsSpace except NEL (U+0085), LS (U+2028) and PS
+	// (U+2029).
+	Words int64
+	// MaxLineLength is the display width of the longest line (wc -L). Tabs are
+	// expanded to the multiple of 8 columns, East Asian wide characters are two
+	// columns and non-printable characters are zero columns.
+	MaxLineLength int64
+}
+
+// ----------------------------------------------------------------------------
+//  Stats
+// ----------------------------------------------------------------------------
+
+// Stats returns the number of lines, bytes, runes, words and the length of the
+// longest line of the input in a single pass.
+//
+// The input is read in chunks and each chunk is scanned in parallel. Multibyte
+// characters and words split across the chunks are counted correctly.
+func Stats(inputReader io.Reader) (Result, error) {
+	if inputReader == nil {
+		return Result{}, ErrNilReader
+	}
+
+	scanner := chunkScanner[statsPartial]{
+		holdBack: holdBackRune,
+		scan:     scanStats,
+		merge:    mergeStats,
+	}
+
+	partial, err := scanner.scanStream(context.Background(), inputReader, numWorkers())
+	if err != nil {
+		if readErr := (*ReadError)(nil); errors.As(err, &readErr) {
+			readErr.Lines = partial.result().Lines
+		}
+
+		return Result{}, err
+	}
+
+	return partial.result(), nil
+}
+
+// ----------------------------------------------------------------------------
+//  Type: statsPartial
+// ----------------------------------------------------------------------------
+
+// statsPartial is the mergeable statistics of a chunk of the input.
+type statsPartial struct {
+	// first is the segment of the line before the first line break. If the chunk
+	// has no line break, it is the segment of the whole chunk.
+	first lineSegment
+	// last is the segment of the line after the last line break.
+	last         lineSegment
+	lines        int64
+	bytes        int64
+	runes        int64
+	words        int64
+	maxWidth     int64 // width of the longest line between the first and last segment
+	hasLF        bool  // true if the chunk has at least one line break
+	startsInWord bool  // true if the chunk begins with a non-space character
+	endsInWord   bool  // true if the chunk ends with a non-space character
+	lastByte     byte  // the last non-NUL byte of the chunk
+}
+
+// scanStats returns the statistics of the chunk. The chunk must not end with an
+// incomplete UTF-8 sequence unless it is the end of the input.
+func scanStats(chunk []byte) statsPartial {
+	partial := statsPartial{bytes: int64(len(chunk))}
+	segment := &partial.first
+	inWord := false
+
+	for index := 0; index < len(chunk); {
+		char, size := rune(chunk[index]), 1
+		if char >= utf8.RuneSelf {
+			char, size = utf8.DecodeRune(chunk[index:])
+		}
+
+		partial.runes++
+
+		if isWordSeparator(char) {
+			inWord = false
+		} else if !inWord {
+			inWord = true
+			partial.words++
+			partial.startsInWord = partial.startsInWord || index == 0
+		}
+
+		switch char {
+		case '\n':
+			partial.lines++
+
+			// Segments after the first line break are complete lines.
+			if partial.hasLF {
+				partial.maxWidth = max(partial.maxWidth, segment.width())
+			}
+
+			partial.hasLF = true
+			segment = &partial.last
+			*segment = lineSegment{}
+		case '\t':
+			segment.tab()
+		default:
+			segment.add(runeWidth(char))
+		}
+
+		index += size
+	}
+
+	partial.endsInWord = inWord
+
+	if i := lastNonNULIndex(chunk); i >= 0 {
+		partial.lastByte = chunk[i]
+	}
+
+	return partial
+}
+
+// mergeStats returns the statistics of the "left" chunk followed by the "right"
+// chunk.
+func mergeStats(left, right statsPartial) statsPartial {
+	merged := statsPartial{
+		lines:        left.lines + right.lines,
+		bytes:        left.bytes + right.bytes,
+		runes:        left.runes + right.runes,
+		words:        left.words + right.words,
+		maxWidth:     max(left.maxWidth, right.maxWidth),
+		hasLF:        left.hasLF || right.hasLF,
+		startsInWord: left.startsInWord,
+		endsInWord:   right.endsInWord,
+		lastByte:     left.lastByte,
+	}
+
+	// A word split across the chunks
+	if left.endsInWord && right.startsInWord {
+		merged.words--
+	}
+
+	if right.lastByte != '\x00' {
+		merged.lastByte = right.lastByte
+	}
+
+	switch {
+	case !left.hasLF && !right.hasLF:
+		merged.first = left.first.join(right.first)
+	case !left.hasLF:
+		merged.first = left.first.join(right.first)
+		merged.last = right.last
+	case !right.hasLF:
+		merged.first = left.first
+		merged.last = left.last.join(right.first)
+	default:
+		// The last line of the left and the first line of the right is a line.
+		merged.first = left.first
+		merged.last = right.last
+		merged.maxWidth = max(merged.maxWidth, left.last.join(right.first).width())
+	}
+
+	return merged
+}
+
+// result returns the statistics of the whole input.
+func (p statsPartial) result() Result {
+	lines := p.lines
+	maxWidth := p.first.width()
+
+	if p.hasLF {
+		maxWidth = max(maxWidth, p.maxWidth, p.last.width())
+	}
+
+	// Count the last line without a line break. Trailing NULs are ignored the
+	// same as CountLines.
+	if p.lastByte != '\x00' && p.lastByte != '\n' {
+		lines++
+	}
+
+	return Result{
+		Lines:         lines,
+		Bytes:         p.bytes,
+		Runes:         p.runes,
+		Words:         p.words,
+		MaxLineLength: maxWidth,
+	}
+}
+
+// ----------------------------------------------------------------------------
+//  Type: lineSegment
+// ----------------------------------------------------------------------------
+
+// lineSegment is the display width of a part of a line. Since the width of a tab
+// depends on the column where it begins, the width is split into the part before
+// the first tab and the part after it.
+type lineSegment struct {
+	head   int64 // width before the first tab
+	tail   int64 // width after the first tab, relative to its tab stop
+	hasTab bool
+}
+
+// add adds the width of a character to the segment.
+func (s *lineSegment) add(width int64) {
+	if s.hasTab {
+		s.tail += width
+
+		return
+	}
+
+	s.head += width
+}
+
+// tab adds a tab to the segment.
+func (s *lineSegment) tab() {
+	if s.hasTab {
+		// The column after the first tab is always a tab stop. So the next tab
+		// stop can be calculated relatively.
+		s.tail = nextTabStop(s.tail)
+
+		return
+	}
+
+	s.hasTab = true
+}
+
+// join returns the segment of "s" followed by "next".
+func (s lineSegment) join(next lineSegment) lineSegment {
+	switch {
+	case !s.hasTab:
+		return lineSegment{head: s.head + next.head, tail: next.tail, hasTab: next.hasTab}
+	case !next.hasTab:
+		return lineSegment{head: s.head, tail: s.tail + next.head, hasTab: true}
+	default:
+		return lineSegment{head: s.head, tail: nextTabStop(s.tail+next.head) + next.tail, hasTab: true}
+	}
+}
+
+// width returns the display width of the segment beginning at the first column.
+func (s lineSegment) width() int64 {
+	if !s.hasTab {
+		return s.head
+	}
+
+	return nextTabStop(s.head) + s.tail
+}
+
+// ----------------------------------------------------------------------------
+//  Helper functions
+// ----------------------------------------------------------------------------
+
+// holdBackRune returns the length of the incomplete UTF-8 sequence at the end of
+// the chunk to be carried over to the next chunk.
+func holdBackRune(chunk []byte) int {
+	for lenTail := 1; lenTail < utf8.UTFMax && lenTail <= len(chunk); lenTail++ {
+		tail := chunk[len(chunk)-lenTail:]
+
+		if utf8.RuneStart(tail[0]) {
+			if utf8.FullRune(tail) {
+				return 0
+			}
+
+			return lenTail
+		}
+	}
+
+	return 0
+}
+
+// isWordSeparator returns true if the character separates the words the same as
+// "wc -w" in a UTF-8 locale. glibc does not class NEL, LS and PS as spaces.
+func isWordSeparator(char rune) bool {
+	switch char {
+	case '\u0085', '\u2028', '\u2029':
+		return false
+	}
+
+	return isSpace(char)
+}
+
+// isSpace returns true if the character is a white space.
+func isSpace(char rune) bool {
+	if char < utf8.RuneSelf {
+		return char == ' ' || ('\t' <= char && char <= '\r')
+	}
+
+	return unicode.IsSpace(char)
+}
+
+// nextTabStop returns the column of the next tab stop from the given column.
+func nextTabStop(column int64) int64 {
+	return (column/tabWidth + 1) * tabWidth
+}
+
+// runeWidth returns the display width of the character.
+func runeWidth(char rune) int64 {
+	switch {
+	case char == utf8.RuneError, !unicode.IsGraphic(char):
+		return 0
+	case char < utf8.RuneSelf:
+		return 1
+	case unicode.In(char, unicode.Mn, unicode.Me):
+		return 0 // combining characters
+	}
+
+	switch width.LookupRune(char).Kind() { //nolint:exhaustive // others are one column
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2 //nolint:mnd // wide characters are two columns
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,234 @@
+package cl
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+//  Tests
+// ============================================================================
+
+//nolint:funlen // long due to the test cases
+func TestStats(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		input  string
+		expect Result
+	}{
+		{input: "", expect: Result{}},
+		{
+			input:  "Hello",
+			expect: Result{Lines: 1, Bytes: 5, Runes: 5, Words: 1, MaxLineLength: 5},
+		},
+		{
+			input:  "Hello World\n",
+			expect: Result{Lines: 1, Bytes: 12, Runes: 12, Words: 2, MaxLineLength: 11},
+		},
+		{
+			input:  "\n\n",
+			expect: Result{Lines: 2, Bytes: 2, Runes: 2, Words: 0, MaxLineLength: 0},
+		},
+		{
+			input:  "  Hello \n World  \n\n foo",
+			expect: Result{Lines: 4, Bytes: 23, Runes: 23, Words: 3, MaxLineLength: 8},
+		},
+		{
+			input:  "a\tb\n\tx\n12345678\tx",
+			expect: Result{Lines: 3, Bytes: 17, Runes: 17, Words: 5, MaxLineLength: 17},
+		},
+		{
+			input:  "こんにちは\n",
+			expect: Result{Lines: 1, Bytes: 16, Runes: 6, Words: 1, MaxLineLength: 10},
+		},
+		{
+			input:  "héllo wörld",
+			expect: Result{Lines: 1, Bytes: 13, Runes: 11, Words: 2, MaxLineLength: 11},
+		},
+		{
+			input:  "a\u3000b", // ideographic space is a wide white space
+			expect: Result{Lines: 1, Bytes: 5, Runes: 3, Words: 2, MaxLineLength: 4},
+		},
+		{
+			input:  "a\u0085b\u2028c\u2029d\u00a0e", // NEL, LS and PS do not separate words as wc
+			expect: Result{Lines: 1, Bytes: 15, Runes: 9, Words: 2, MaxLineLength: 6},
+		},
+		{
+			input:  "e\u0301\r\n", // combining character and CR are zero width
+			expect: Result{Lines: 1, Bytes: 5, Runes: 4, Words: 1, MaxLineLength: 1},
+		},
+		{
+			input:  "\xff\xfe", // invalid UTF-8 sequence
+			expect: Result{Lines: 1, Bytes: 2, Runes: 2, Words: 1, MaxLineLength: 0},
+		},
+		{
+			input:  "Hello\n\x00\x00", // trailing NULs are ignored to count lines
+			expect: Result{Lines: 1, Bytes: 8, Runes: 8, Words: 2, MaxLineLength: 5},
+		},
+	} {
+		actual, err := Stats(strings.NewReader(test.input))
+
+		require.NoError(t, err)
+		require.Equal(t, test.expect, actual, "input: %q", test.input)
+
+		// Split the input into chunks of one byte
+		actual, err = Stats(iotest.OneByteReader(strings.NewReader(test.input)))
+
+		require.NoError(t, err)
+		require.Equal(t, test.expect, actual, "input read byte by byte: %q", test.input)
+	}
+}
+
+func TestStats_split_chunks(t *testing.T) {
+	t.Parallel()
+
+	//nolint:gosec // weak random generator is enough for testing
+	rnd := rand.New(rand.NewPCG(1, 2))
+	pieces := []string{
+		"a", "bc", " ", "\t", "\n", "\r\n", "é", "こ", "\u3000", "\u0301", "\xe2\x80", "\xff", "\x00",
+	}
+
+	for range 50 {
+		var builder strings.Builder
+
+		for range rnd.IntN(chunkSize / 4) {
+			builder.WriteString(pieces[rnd.IntN(len(pieces))])
+		}
+
+		input := builder.String()
+		expect := scanStats([]byte(input)).result()
+
+		for _, newReader := range []func(io.Reader) io.Reader{
+			iotest.OneByteReader,
+			iotest.HalfReader,
+			func(r io.Reader) io.Reader { return r },
+		} {
+			for _, numWorker := range []int{1, 4} {
+				reader := newReader(strings.NewReader(input))
+				scanner := chunkScanner[statsPartial]{
+					holdBack: holdBackRune,
+					scan:     scanStats,
+					merge:    mergeStats,
+				}
+
+				actual, err := scanner.scanStream(context.Background(), reader, numWorker)
+
+				require.NoError(t, err)
+				require.Equal(t, expect, actual.result(), "input: %q", input)
+			}
+		}
+	}
+}
+
+func TestStats_nil_input(t *testing.T) {
+	t.Parallel()
+
+	result, err := Stats(nil)
+
+	require.Error(t, err)
+	require.Zero(t, result, "returned result should be zero on error")
+	require.Contains(t, err.Error(), "given reader is nil")
+}
+
+func TestStats_io_read_fail(t *testing.T) {
+	t.Parallel()
+
+	result, err := Stats(&DummyReader{})
+
+	require.Error(t, err)
+	require.Zero(t, result, "returned result should be zero on error")
+	require.Contains(t, err.Error(), "failed to read from reader")
+	require.Contains(t, err.Error(), "forced error", "the error should contain the reason of the error")
+}
+
+func Test_chunkScanner_canceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	scanner := chunkScanner[int]{
+		holdBack: func([]byte) int { return 0 },
+		scan:     func(chunk []byte) int { return len(chunk) },
+		merge:    func(left, right int) int { return left + right },
+	}
+
+	total, err := scanner.scanStream(ctx, strings.NewReader("Hello"), 2)
+
+	require.ErrorIs(t, err, context.Canceled)
+	require.Zero(t, total)
+}
+
+func Test_chunkScanner_large_carry(t *testing.T) {
+	t.Parallel()
+
+	numChunks := 0
+
+	// Hold back everything until EOF. The carry grows larger than a chunk.
+	scanner := chunkScanner[int]{
+		holdBack: func(chunk []byte) int { return len(chunk) },
+		scan: func(chunk []byte) int {
+			numChunks++
+
+			return len(chunk)
+		},
+		merge: func(left, right int) int { return left + right },
+	}
+
+	input := strings.Repeat("a", chunkSize*5)
+
+	total, err := scanner.scanStream(context.Background(), strings.NewReader(input), 1)
+
+	require.NoError(t, err)
+	require.Equal(t, len(input), total)
+	require.Equal(t, 1, numChunks, "the whole input should be carried over to a single chunk")
+}
+
+func Test_holdBackRune(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		input  string
+		expect int
+	}{
+		{input: "", expect: 0},
+		{input: "abc", expect: 0},
+		{input: "abc\xe3", expect: 1},
+		{input: "abc\xe3\x81", expect: 2},
+		{input: "abc\xe3\x81\x93", expect: 0},
+		{input: "abc\xf0\x9f\x98", expect: 3},
+		{input: "\x80\x80\x80", expect: 0},
+		{input: "abc\xff", expect: 0},
+	} {
+		require.Equal(t, test.expect, holdBackRune([]byte(test.input)), "input: %q", test.input)
+	}
+}
+
+func Test_runeWidth(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		char   rune
+		expect int64
+	}{
+		{char: 'a', expect: 1},
+		{char: ' ', expect: 1},
+		{char: '\x00', expect: 0},
+		{char: '\r', expect: 0},
+		{char: 'é', expect: 1},
+		{char: 'こ', expect: 2},
+		{char: 'Ａ', expect: 2},
+		{char: '\u0301', expect: 0},
+		{char: '\u200b', expect: 0},
+		{char: '\ufffd', expect: 0},
+	} {
+		require.Equal(t, test.expect, runeWidth(test.char), "char: %q", test.char)
+	}
+}
@@ -0,0 +1,46 @@
+package strategy_test
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"github.com/KEINOS/go-countline/cl"
+	"github.com/KEINOS/go-countline/cl/strategy"
+)
+
+func ExampleLookup() {
+	for _, name := range []string{strategy.Default, strategy.ReadLine, strategy.Scanner} {
+		found, ok := strategy.Lookup(name)
+		if !ok {
+			log.Fatalf("strategy not registered: %s", name)
+		}
+
+		count, err := cl.CountLinesWithOptions(strings.NewReader("foo\nbar\nbuzz\n"), cl.WithStrategy(found))
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Println(name, count)
+	}
+	// Output:
+	// default 3
+	// readline 3
+	// scanner 3
+}
+
+func ExampleRegister() {
+	// Count the lines by reading the whole input at once
+	strategy.Register("readall", strategy.Func(func(inputReader io.Reader) (int, error) {
+		data, err := io.ReadAll(inputReader)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read: %w", err)
+		}
+
+		return strings.Count(string(data), "\n"), nil
+	}))
+
+	fmt.Println(strategy.Names())
+	// Output: [byteloop default fanout readall readline scanner transform]
+}
@@ -0,0 +1,41 @@
+package strategy
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+//  ReadLine
+// ----------------------------------------------------------------------------
+
+// countReadLine uses bufio.Reader.ReadLine to count the number of lines.
+func countReadLine(inputReader io.Reader) (int, error) {
+	if inputReader == nil {
+		return 0, errors.New("given reader is nil")
+	}
+
+	bufReader := bufio.NewReader(inputReader)
+	count := 0
+
+	for {
+		_, isPrefix, err := bufReader.ReadLine()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+
+			return 0, errors.Wrap(err, "failed to read from reader")
+		}
+
+		if isPrefix {
+			continue
+		}
+
+		count++
+	}
+
+	return count, nil
+}
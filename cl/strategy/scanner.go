@@ -0,0 +1,50 @@
+package strategy
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// bufSizeDefault is the initial buffer size of the scanner.
+const bufSizeDefault = 1024
+
+// ----------------------------------------------------------------------------
+//  Scanner
+// ----------------------------------------------------------------------------
+
+// countScanner uses bufio.Scanner to count the number of lines.
+func countScanner(inputReader io.Reader) (int, error) {
+	return countScannerSize(inputReader, bufSizeDefault)
+}
+
+// countScannerSize counts the lines with the buffer of the size. It retries with
+// a larger buffer if a line is too long for it.
+func countScannerSize(inputReader io.Reader, bufSize int) (int, error) {
+	if inputReader == nil {
+		return 0, errors.New("given reader is nil")
+	}
+
+	bufScanner := bufio.NewScanner(inputReader)
+
+	buf := make([]byte, bufSize)
+	bufScanner.Buffer(buf, bufSize)
+
+	countLine := 0
+
+	for bufScanner.Scan() {
+		countLine++
+	}
+
+	err := bufScanner.Err()
+	if err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return countScannerSize(inputReader, bufSize*bufSizeDefault)
+		}
+
+		return 0, errors.Wrap(err, "failed to scan reader")
+	}
+
+	return countLine, nil
+}
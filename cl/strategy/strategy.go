@@ -0,0 +1,125 @@
+/*
+Package strategy is the registry of the implementations to count the lines, so
+that they can be swapped and compared without copying the code.
+
+The alternate implementations, which were once candidates of CountLines, are
+registered under the stable names of the constants below. The implementation of
+cl.CountLines is registered as "default" once the cl package is imported.
+
+	s, ok := strategy.Lookup(strategy.ByteLoop)
+	if !ok {
+		...
+	}
+
+	count, err := cl.CountLinesWithOptions(input, cl.WithStrategy(s))
+*/
+package strategy
+
+import (
+	"io"
+	"slices"
+	"sync"
+)
+
+// Stable names of the registered strategies.
+const (
+	// Default is the implementation of cl.CountLines. It is registered by the cl
+	// package.
+	Default = "default"
+	// Transform counts the line breaks with a golang.org/x/text/transform
+	// Transformer.
+	Transform = "transform"
+	// Fanout reads the input in growing chunks and counts them in goroutines.
+	Fanout = "fanout"
+	// ByteLoop counts the line breaks byte by byte without goroutines.
+	ByteLoop = "byteloop"
+	// ReadLine counts the lines with bufio.Reader.ReadLine.
+	ReadLine = "readline"
+	// Scanner counts the lines with bufio.Scanner.
+	Scanner = "scanner"
+)
+
+// ----------------------------------------------------------------------------
+//  Type: Strategy
+// ----------------------------------------------------------------------------
+
+// Strategy is an implementation to count the lines.
+type Strategy interface {
+	// CountLines counts the number of lines in the input, the same as
+	// cl.CountLines. It must return an error on nil input.
+	CountLines(inputReader io.Reader) (int, error)
+}
+
+// Func is an adapter to use an ordinary function as a Strategy.
+type Func func(inputReader io.Reader) (int, error)
+
+// CountLines calls f(inputReader).
+func (f Func) CountLines(inputReader io.Reader) (int, error) {
+	return f(inputReader)
+}
+
+// ----------------------------------------------------------------------------
+//  Registry
+// ----------------------------------------------------------------------------
+
+var (
+	mu       sync.RWMutex
+	registry = map[string]Strategy{
+		Transform: Func(countTransform),
+		Fanout:    Func(countFanout),
+		ByteLoop:  Func(countByteLoop),
+		ReadLine:  Func(countReadLine),
+		Scanner:   Func(countScanner),
+	}
+)
+
+// Register makes the strategy available by the name. It is meant to be called
+// from the init function of the package that implements the strategy.
+//
+// It panics if the name is empty, the strategy is nil or the name is already
+// registered, the same as database/sql.Register.
+func Register(name string, strategy Strategy) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if name == "" {
+		panic("strategy: Register with empty name")
+	}
+
+	if strategy == nil {
+		panic("strategy: Register strategy is nil: " + name)
+	}
+
+	if _, dup := registry[name]; dup {
+		panic("strategy: Register called twice for strategy " + name)
+	}
+
+	registry[name] = strategy
+}
+
+// Lookup returns the strategy registered by the name. The ok is false if no such
+// strategy is registered.
+func Lookup(name string) (Strategy, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	strategy, ok := registry[name]
+
+	return strategy, ok
+}
+
+// Names returns the sorted names of the registered strategies.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+
+	for name := range registry {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	return names
+}
@@ -0,0 +1,151 @@
+package strategy
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/KEINOS/go-countline/cl/spec"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+//  Tests
+// ============================================================================
+//  Specification tests for the built-in strategies.
+
+func TestBuiltin_specs(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{Transform, Fanout, ByteLoop, ReadLine, Scanner} {
+		found, ok := Lookup(name)
+		require.True(t, ok, "built-in strategy should be registered: %s", name)
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			spec.RunSpecTest(t, name, found.CountLines)
+		})
+
+		t.Run(name+"_nil_input", func(t *testing.T) {
+			t.Parallel()
+
+			numLines, err := found.CountLines(nil)
+
+			require.Error(t, err, "should return an error on nil input")
+			require.Equal(t, 0, numLines, "returned number of lines should be 0 on error")
+		})
+
+		t.Run(name+"_io_read_fail", func(t *testing.T) {
+			t.Parallel()
+
+			numLines, err := found.CountLines(&DummyReader{msg: "forced error"})
+
+			require.Error(t, err, "it should return an error on io.Reader read failure")
+			require.Equal(t, 0, numLines, "returned number of lines should be 0 on error")
+			require.Contains(t, err.Error(), "forced error", "the returned error should contain the reason of the error")
+		})
+
+		t.Run(name+"_data_with_eof", func(t *testing.T) {
+			t.Parallel()
+
+			// The last data returned along with io.EOF, such as by gzip.Reader
+			numLines, err := found.CountLines(iotest.DataErrReader(strings.NewReader("foo\nbar\nbuzz")))
+
+			require.NoError(t, err)
+			require.Equal(t, 3, numLines, "data returned with io.EOF should be counted")
+		})
+
+		t.Run(name+"_zero_padded", func(t *testing.T) {
+			t.Parallel()
+
+			_, err := found.CountLines(bytes.NewReader(make([]byte, 1024)))
+
+			require.NoError(t, err, "it should not return an error on zero padded/empty capped byte slice input")
+		})
+	}
+}
+
+func TestLineCounter_Reset(t *testing.T) {
+	t.Parallel()
+
+	counter := new(lineCounter)
+
+	_, _, err := counter.Transform(nil, []byte("foo\nbar"), true)
+	require.NoError(t, err)
+
+	counter.Reset()
+
+	require.Equal(t, lineCounter{}, *counter)
+}
+
+func TestCountScanner_long_line(t *testing.T) {
+	t.Parallel()
+
+	// A line longer than the initial buffer makes the scanner retry with a
+	// larger buffer instead of failing.
+	_, err := countScanner(strings.NewReader(strings.Repeat("a", bufSizeDefault*2) + "\nb\n"))
+
+	require.NoError(t, err)
+}
+
+//nolint:paralleltest // do not parallelize due to changing the registry
+func TestRegister(t *testing.T) {
+	const name = "test_register"
+
+	defer func() {
+		mu.Lock()
+		delete(registry, name)
+		mu.Unlock()
+	}()
+
+	_, ok := Lookup(name)
+	require.False(t, ok, "unregistered name should not be found")
+
+	Register(name, Func(func(io.Reader) (int, error) { return 42, nil }))
+
+	found, ok := Lookup(name)
+	require.True(t, ok)
+
+	numLines, err := found.CountLines(strings.NewReader(""))
+
+	require.NoError(t, err)
+	require.Equal(t, 42, numLines)
+	require.Contains(t, Names(), name)
+
+	require.PanicsWithValue(t, "strategy: Register called twice for strategy "+name, func() {
+		Register(name, Func(countByteLoop))
+	})
+	require.PanicsWithValue(t, "strategy: Register with empty name", func() {
+		Register("", Func(countByteLoop))
+	})
+	require.PanicsWithValue(t, "strategy: Register strategy is nil: nil_strategy", func() {
+		Register("nil_strategy", nil)
+	})
+}
+
+func TestNames(t *testing.T) {
+	t.Parallel()
+
+	names := Names()
+
+	require.IsNonDecreasing(t, names, "names should be sorted")
+	require.Subset(t, names, []string{ByteLoop, Fanout, ReadLine, Scanner, Transform})
+}
+
+// ============================================================================
+//  Helpers
+// ============================================================================
+
+// DummyReader is a dummy io.Reader that returns an error on Read().
+type DummyReader struct {
+	msg string
+}
+
+// Read implements io.Reader interface. This method always returns an error with the msg field.
+func (r *DummyReader) Read(_ []byte) (int, error) {
+	return 0, errors.New(r.msg)
+}
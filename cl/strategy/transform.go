@@ -0,0 +1,72 @@
+package strategy
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/transform"
+)
+
+// ----------------------------------------------------------------------------
+//  Transform
+// ----------------------------------------------------------------------------
+
+// countTransform uses a Transformer to count the number of lines in a file.
+func countTransform(inputReader io.Reader) (int, error) {
+	if inputReader == nil {
+		return 0, errors.New("given reader is nil")
+	}
+
+	bufReader := new(lineCounter)
+	transformer := transform.NewReader(inputReader, bufReader)
+
+	_, err := io.ReadAll(transformer)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to read the file")
+	}
+
+	count := bufReader.count
+	if bufReader.hasFragments {
+		count++
+	}
+
+	return count, nil
+}
+
+// lineCounter is a Transformer implementation to count lines.
+type lineCounter struct {
+	lenRead      int
+	count        int
+	hasFragments bool
+}
+
+// Transform is the implementation of the Transformer interface.
+//
+//nolint:nonamedreturns // named returns are used for clarity to match the interface
+func (lc *lineCounter) Transform(_, src []byte, _ bool) (nDst, nSrc int, err error) {
+	readBytes := 0
+
+	for _, value := range src {
+		readBytes++
+
+		if value == '\n' {
+			lc.count++
+			lc.hasFragments = false
+
+			continue
+		}
+
+		lc.hasFragments = true
+	}
+
+	lc.lenRead += readBytes
+
+	return readBytes, readBytes, nil
+}
+
+// Reset resets the internal state of lineCounter.
+func (lc *lineCounter) Reset() {
+	lc.lenRead = 0
+	lc.count = 0
+	lc.hasFragments = false
+}
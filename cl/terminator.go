@@ -0,0 +1,195 @@
+package cl
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/KEINOS/go-countline/cl/internal/countbyte"
+)
+
+// ----------------------------------------------------------------------------
+//  Type: Terminator
+// ----------------------------------------------------------------------------
+
+// Terminator is a set of line terminators to count. Combine them with "|" to
+// count more than one kind of terminator. e.g. "cl.LF | cl.CRLF | cl.CR".
+//
+// The zero value is the same as LF.
+type Terminator uint8
+
+const (
+	// LF is the line feed "\n" (Unix).
+	LF Terminator = 1 << iota
+	// CRLF is the pair of carriage return and line feed "\r\n" (Windows). When
+	// combined with LF or CR, the pair is counted as a single line break.
+	CRLF
+	// CR is the carriage return "\r" (classic Mac OS).
+	CR
+	// Unicode is the Unicode line separators encoded in UTF-8. Which are NEL
+	// (U+0085), LS (U+2028) and PS (U+2029).
+	Unicode
+)
+
+// lenMaxTerminator is the maximum byte length of the terminators.
+const lenMaxTerminator = 3
+
+//nolint:gochecknoglobals // byte sequences of the terminators
+var (
+	seqLF   = []byte("\n")
+	seqCR   = []byte("\r")
+	seqCRLF = []byte("\r\n")
+	seqNEL  = []byte("\u0085")
+	seqLS   = []byte("\u2028")
+	seqPS   = []byte("\u2029")
+)
+
+// String returns the names of the terminators in the set joined with "|".
+func (t Terminator) String() string {
+	names := []string{}
+
+	for _, term := range []struct {
+		name string
+		flag Terminator
+	}{
+		{"LF", LF},
+		{"CRLF", CRLF},
+		{"CR", CR},
+		{"Unicode", Unicode},
+	} {
+		if t&term.flag != 0 {
+			names = append(names, term.name)
+		}
+	}
+
+	return strings.Join(names, "|")
+}
+
+// orDefault returns LF if the set is empty.
+func (t Terminator) orDefault() Terminator {
+	if t == 0 {
+		return LF
+	}
+
+	return t
+}
+
+// count returns the number of terminators in the buffer.
+//
+// The result of the buffers split from an input does not sum up to the result of
+// the whole input if a terminator is split across them. Use straddle to correct
+// the sum.
+func (t Terminator) count(buf []byte) uint64 {
+	if t == LF {
+		//nolint:gosec // bytes.Count never returns a negative value
+		return uint64(bytes.Count(buf, seqLF)) // fast path
+	}
+
+	found := 0
+
+	switch t & (LF | CR) {
+	case LF | CR:
+		found, _ = countbyte.Count(buf, '\n', '\r') // both in a single pass
+	case LF:
+		found = bytes.Count(buf, seqLF)
+	case CR:
+		found = bytes.Count(buf, seqCR)
+	}
+
+	if t&CRLF != 0 {
+		pairs := bytes.Count(buf, seqCRLF)
+		found += pairs
+
+		// The pair is a single terminator. Do not count its CR and LF separately.
+		if t&LF != 0 {
+			found -= pairs
+		}
+
+		if t&CR != 0 {
+			found -= pairs
+		}
+	}
+
+	if t&Unicode != 0 {
+		found += bytes.Count(buf, seqNEL) + bytes.Count(buf, seqLS) + bytes.Count(buf, seqPS)
+	}
+
+	//nolint:gosec // found is never negative since pairs <= number of CR and LF
+	return uint64(found)
+}
+
+// straddle returns the correction for the sum of the counts of two adjacent parts
+// of the input. "left" is the last bytes of the input before "right".
+//
+// The correction may be negative, e.g. "\r" + "\n" with "LF|CR|CRLF". Adding it
+// as uint64 wraps around to the right value since the total is never negative.
+func (t Terminator) straddle(left, right []byte) uint64 {
+	// Single-byte terminators never straddle
+	if t&(CRLF|Unicode) == 0 || len(left) == 0 || len(right) == 0 {
+		return 0
+	}
+
+	left = left[max(len(left)-(lenMaxTerminator-1), 0):]
+	right = right[:min(len(right), lenMaxTerminator-1)]
+
+	window := make([]byte, 0, len(left)+len(right))
+	window = append(append(window, left...), right...)
+
+	return t.count(window) - t.count(left) - t.count(right)
+}
+
+// endsWith returns true if the buffer ends with one of the terminators.
+func (t Terminator) endsWith(buf []byte) bool {
+	return (t&LF != 0 && bytes.HasSuffix(buf, seqLF)) ||
+		(t&CR != 0 && bytes.HasSuffix(buf, seqCR)) ||
+		(t&CRLF != 0 && bytes.HasSuffix(buf, seqCRLF)) ||
+		(t&Unicode != 0 && (bytes.HasSuffix(buf, seqNEL) ||
+			bytes.HasSuffix(buf, seqLS) ||
+			bytes.HasSuffix(buf, seqPS)))
+}
+
+// ----------------------------------------------------------------------------
+//  Type: trailer
+// ----------------------------------------------------------------------------
+
+// trailer keeps the last bytes of the input read so far. It is used to correct
+// the count of terminators split across chunks and to detect the fragment.
+type trailer struct {
+	// tail is the last bytes of the input.
+	tail []byte
+	// body is the last bytes of the input ignoring trailing NULs.
+	body []byte
+}
+
+// push updates the trailer with the next chunk of the input.
+func (tr *trailer) push(chunk []byte) {
+	if i := lastNonNULIndex(chunk); i >= 0 {
+		tr.body = lastBytes(tr.body[:0], tr.tail, chunk[:i+1])
+	}
+
+	tr.tail = lastBytes(tr.tail, tr.tail, chunk)
+}
+
+// merge updates the trailer with the trailer of the next part of the input.
+func (tr *trailer) merge(next trailer) {
+	if len(next.body) > 0 {
+		tr.body = lastBytes(tr.body[:0], tr.tail, next.body)
+	}
+
+	tr.tail = lastBytes(tr.tail, tr.tail, next.tail)
+}
+
+// hasFragment returns true if the input ends without a terminator. Trailing NULs
+// are ignored.
+func (tr *trailer) hasFragment(term Terminator) bool {
+	return len(tr.body) > 0 && !term.endsWith(tr.body)
+}
+
+// lastBytes returns the last lenMaxTerminator bytes of "head + chunk" stored in
+// dst. dst may share the memory with head.
+func lastBytes(dst, head, chunk []byte) []byte {
+	var scratch [lenMaxTerminator * 2]byte
+
+	joined := append(append(scratch[:0], head...), chunk[max(len(chunk)-lenMaxTerminator, 0):]...)
+
+	return append(dst[:0], joined[max(len(joined)-lenMaxTerminator, 0):]...)
+}
@@ -0,0 +1,117 @@
+package cl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTerminator_String(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		expect string
+		term   Terminator
+	}{
+		{term: 0, expect: ""},
+		{term: LF, expect: "LF"},
+		{term: CRLF, expect: "CRLF"},
+		{term: CR, expect: "CR"},
+		{term: Unicode, expect: "Unicode"},
+		{term: LF | CRLF | CR, expect: "LF|CRLF|CR"},
+		{term: CR | Unicode, expect: "CR|Unicode"},
+	} {
+		require.Equal(t, test.expect, test.term.String())
+	}
+}
+
+func TestTerminator_orDefault(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, LF, Terminator(0).orDefault(), "zero value should be LF")
+	require.Equal(t, CRLF, CRLF.orDefault())
+}
+
+func TestTerminator_straddle(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		left   string
+		right  string
+		term   Terminator
+		expect int64
+	}{
+		{term: LF, left: "a\r", right: "\nb", expect: 0},
+		{term: CRLF, left: "a\r", right: "\nb", expect: 1},
+		{term: CRLF, left: "", right: "\nb", expect: 0},
+		{term: CRLF, left: "a\r", right: "", expect: 0},
+		{term: LF | CRLF, left: "a\r", right: "\nb", expect: 0},
+		{term: LF | CRLF | CR, left: "a\r", right: "\nb", expect: -1},
+		{term: Unicode, left: "a\xe2", right: "\x80\xa8", expect: 1},
+		{term: Unicode, left: "\xe2\x80", right: "\xa8b", expect: 1},
+		{term: Unicode, left: "\xc2", right: "\x85", expect: 1},
+		{term: Unicode, left: "abc", right: "def", expect: 0},
+	} {
+		actual := int64(test.term.straddle([]byte(test.left), []byte(test.right))) //nolint:gosec // wrap around on purpose
+
+		require.Equal(t, test.expect, actual, "term: %v, left: %q, right: %q", test.term, test.left, test.right)
+	}
+}
+
+func TestTerminator_endsWith(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		input  string
+		term   Terminator
+		expect bool
+	}{
+		{term: LF, input: "a\n", expect: true},
+		{term: LF, input: "a\r", expect: false},
+		{term: CR, input: "a\r", expect: true},
+		{term: CRLF, input: "a\r\n", expect: true},
+		{term: CRLF, input: "a\n", expect: false},
+		{term: Unicode, input: "a\u0085", expect: true},
+		{term: Unicode, input: "a\u2028", expect: true},
+		{term: Unicode, input: "a\u2029", expect: true},
+		{term: Unicode, input: "a\n", expect: false},
+	} {
+		require.Equal(t, test.expect, test.term.endsWith([]byte(test.input)), "term: %v, input: %q", test.term, test.input)
+	}
+}
+
+func Test_trailer(t *testing.T) {
+	t.Parallel()
+
+	var trail trailer
+
+	require.False(t, trail.hasFragment(LF), "empty input should not have a fragment")
+
+	trail.push([]byte("Hello\r"))
+	require.Equal(t, "lo\r", string(trail.tail))
+	require.True(t, trail.hasFragment(CRLF))
+
+	trail.push([]byte("\n\x00"))
+	require.Equal(t, "\r\n\x00", string(trail.tail))
+	require.Equal(t, "o\r\n", string(trail.body), "body should ignore trailing NULs")
+	require.False(t, trail.hasFragment(CRLF))
+
+	trail.push([]byte("\x00\x00\x00\x00"))
+	require.Equal(t, "\x00\x00\x00", string(trail.tail))
+	require.Equal(t, "o\r\n", string(trail.body), "body should not change by NULs")
+
+	// Merge the trailer of the next part
+	var next trailer
+
+	next.push([]byte("a"))
+	trail.merge(next)
+
+	require.Equal(t, "\x00\x00a", string(trail.tail))
+	require.Equal(t, "\x00\x00a", string(trail.body), "NULs followed by non-NUL are not trailing")
+	require.True(t, trail.hasFragment(LF))
+
+	trail.merge(trailer{tail: []byte("\x00")})
+
+	require.Equal(t, "\x00a\x00", string(trail.tail))
+	require.Equal(t, "\x00\x00a", string(trail.body))
+}
@@ -0,0 +1,54 @@
+package walk_test
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/KEINOS/go-countline/cl/walk"
+)
+
+func ExampleCount() {
+	root, err := os.MkdirTemp("", "example")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	defer os.RemoveAll(root)
+
+	for name, data := range map[string]string{
+		".gitignore":  "*.log\n",
+		"main.go":     "package main\n\nfunc main() {}\n",
+		"app.log":     "ignored by .gitignore\n",
+		"README.md":   "# Title\n",
+		"cmd/tool.go": "package main\n",
+	} {
+		pathFile := filepath.Join(root, filepath.FromSlash(name))
+
+		if err := os.MkdirAll(filepath.Dir(pathFile), 0o700); err != nil {
+			log.Fatal(err)
+		}
+
+		if err := os.WriteFile(pathFile, []byte(data), 0o600); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	result, err := walk.Count(root, walk.Options{Include: []string{"*.go", "*.log"}})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, file := range result.Files {
+		relPath, _ := filepath.Rel(root, file.Path)
+
+		fmt.Println(file.Lines, filepath.ToSlash(relPath))
+	}
+
+	fmt.Println(result.Total, "total")
+	// Output:
+	// 1 cmd/tool.go
+	// 3 main.go
+	// 4 total
+}
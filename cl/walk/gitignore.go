@@ -0,0 +1,150 @@
+package walk
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// nameGitignore is the name of the files of the ignore rules.
+const nameGitignore = ".gitignore"
+
+// ----------------------------------------------------------------------------
+//  Type: ignoreRule
+// ----------------------------------------------------------------------------
+
+// ignoreRule is a pattern line of the .gitignore file.
+type ignoreRule struct {
+	pattern  string
+	negate   bool // "!pattern" re-includes the matched path
+	dirOnly  bool // "pattern/" matches directories only
+	anchored bool // the pattern with a slash matches the path relative to the .gitignore
+}
+
+// match reports whether the path relative to the directory of the .gitignore
+// matches the rule.
+func (r ignoreRule) match(relPath string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+
+	if r.anchored {
+		return matchGlob(r.pattern, relPath)
+	}
+
+	return matchGlob(r.pattern, path.Base(relPath))
+}
+
+// parseGitignore parses the lines of the .gitignore file. The malformed patterns
+// are ignored the same as git.
+func parseGitignore(input io.Reader) ([]ignoreRule, error) {
+	rules := []ignoreRule{}
+	scanner := bufio.NewScanner(input)
+
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		rule := ignoreRule{}
+
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		}
+
+		// Escaped leading "#" and "!"
+		line = strings.TrimPrefix(line, `\`)
+
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+
+		rule.anchored = strings.Contains(line, "/")
+		rule.pattern = strings.TrimPrefix(line, "/")
+
+		if rule.pattern == "" || validateGlob(rule.pattern) != nil {
+			continue
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, errors.Wrap(scanner.Err(), "failed to read .gitignore")
+}
+
+// ----------------------------------------------------------------------------
+//  Type: ignoreTree
+// ----------------------------------------------------------------------------
+
+// ignoreTree is the rules of the .gitignore files in the directories walked so
+// far, keyed by the slash-separated directory path relative to the root.
+type ignoreTree map[string][]ignoreRule
+
+// load reads the .gitignore file in the directory if any.
+func (t ignoreTree) load(root, relDir string) error {
+	osFile, err := os.Open(filepath.Join(root, filepath.FromSlash(relDir), nameGitignore))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return errors.Wrap(err, "failed to open .gitignore")
+	}
+
+	defer osFile.Close()
+
+	rules, err := parseGitignore(osFile)
+	if err != nil {
+		return err
+	}
+
+	t[relDir] = rules
+
+	return nil
+}
+
+// ignored reports whether the path relative to the root is ignored by the rules
+// of the ancestor directories. The rules in the deeper directory and the later
+// lines take precedence.
+func (t ignoreTree) ignored(relPath string, isDir bool) bool {
+	ignored := false
+	relDir := "."
+
+	for {
+		for _, rule := range t[relDir] {
+			if rule.match(relFrom(relDir, relPath), isDir) {
+				ignored = !rule.negate
+			}
+		}
+
+		next := strings.IndexByte(strings.TrimPrefix(relPath, prefixDir(relDir)), '/')
+		if next < 0 {
+			return ignored
+		}
+
+		relDir = relPath[:len(prefixDir(relDir))+next]
+	}
+}
+
+// prefixDir returns the prefix of the paths under the directory.
+func prefixDir(relDir string) string {
+	if relDir == "." {
+		return ""
+	}
+
+	return relDir + "/"
+}
+
+// relFrom returns the path relative to the directory.
+func relFrom(relDir, relPath string) string {
+	return strings.TrimPrefix(relPath, prefixDir(relDir))
+}
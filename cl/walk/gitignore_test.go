@@ -0,0 +1,108 @@
+package walk
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+//  Tests
+// ============================================================================
+
+func Test_parseGitignore(t *testing.T) {
+	t.Parallel()
+
+	rules, err := parseGitignore(strings.NewReader(`
+# comment
+*.log
+!keep.log
+build/
+/root.txt
+docs/*.md
+\#hash
+\!bang
+trailing.txt   
+[
+/
+`))
+
+	require.NoError(t, err)
+	require.Equal(t, []ignoreRule{
+		{pattern: "*.log"},
+		{pattern: "keep.log", negate: true},
+		{pattern: "build", dirOnly: true},
+		{pattern: "root.txt", anchored: true},
+		{pattern: "docs/*.md", anchored: true},
+		{pattern: "#hash"},
+		{pattern: "!bang"},
+		{pattern: "trailing.txt"},
+	}, rules)
+
+	_, err = parseGitignore(iotest.ErrReader(os.ErrClosed))
+	require.ErrorContains(t, err, "failed to read .gitignore")
+}
+
+func Test_ignoreTree_ignored(t *testing.T) {
+	t.Parallel()
+
+	tree := ignoreTree{
+		".": {
+			{pattern: "*.log"},
+			{pattern: "keep.log", negate: true},
+			{pattern: "build", dirOnly: true},
+			{pattern: "root.txt", anchored: true},
+		},
+		"sub": {
+			{pattern: "keep.log"},
+			{pattern: "local.txt", anchored: true},
+		},
+	}
+
+	for _, test := range []struct {
+		path   string
+		isDir  bool
+		expect bool
+	}{
+		{path: "app.log", expect: true},
+		{path: "a/b/app.log", expect: true},
+		{path: "keep.log", expect: false},
+		{path: "sub/keep.log", expect: true},
+		{path: "build", isDir: true, expect: true},
+		{path: "build", isDir: false, expect: false},
+		{path: "a/build", isDir: true, expect: true},
+		{path: "root.txt", expect: true},
+		{path: "a/root.txt", expect: false},
+		{path: "sub/local.txt", expect: true},
+		{path: "sub/a/local.txt", expect: false},
+		{path: "local.txt", expect: false},
+		{path: "main.go", expect: false},
+	} {
+		require.Equal(t, test.expect, tree.ignored(test.path, test.isDir), "path: %s, dir: %v", test.path, test.isDir)
+	}
+}
+
+func Test_ignoreTree_load(t *testing.T) {
+	t.Parallel()
+
+	dirTemp := t.TempDir()
+	tree := ignoreTree{}
+
+	require.NoError(t, tree.load(dirTemp, "."), "missing .gitignore should not be an error")
+	require.Empty(t, tree)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dirTemp, nameGitignore), []byte("*.log\n"), 0o600))
+	require.NoError(t, tree.load(dirTemp, "."))
+	require.Equal(t, ignoreTree{".": {{pattern: "*.log"}}}, tree)
+
+	// .gitignore which is a directory
+	require.NoError(t, os.MkdirAll(filepath.Join(dirTemp, "sub", nameGitignore), 0o700))
+	require.ErrorContains(t, tree.load(dirTemp, "sub"), "failed to read .gitignore")
+
+	// Path under a regular file
+	require.ErrorContains(t, tree.load(dirTemp, nameGitignore), "failed to open .gitignore")
+}
@@ -0,0 +1,70 @@
+package walk
+
+import (
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+//  Glob patterns
+// ----------------------------------------------------------------------------
+
+// matchGlob reports whether the slash-separated path matches the pattern. The
+// pattern is of path.Match and "**" matches zero or more directories, such as
+// "**/testdata" and "docs/**/*.md".
+func matchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+// matchSegments matches the segments of the pattern and the path.
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := range len(name) + 1 {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+
+			return false
+		}
+
+		if len(name) == 0 {
+			return false
+		}
+
+		// The error is checked by validateGlob beforehand
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+
+		pattern, name = pattern[1:], name[1:]
+	}
+
+	return len(name) == 0
+}
+
+// matchName reports whether the slash-separated path relative to the root matches
+// the pattern. The pattern without a slash matches the base name at any depth,
+// such as "*.go", and the one with a slash matches the whole relative path, such
+// as "cmd/*/main.go".
+func matchName(pattern, relPath string) bool {
+	if !strings.Contains(pattern, "/") {
+		return matchGlob(pattern, path.Base(relPath))
+	}
+
+	return matchGlob(strings.TrimPrefix(pattern, "/"), relPath)
+}
+
+// validateGlob returns an error if the pattern is malformed.
+func validateGlob(pattern string) error {
+	for _, segment := range strings.Split(pattern, "/") {
+		if _, err := path.Match(segment, ""); err != nil {
+			return errors.Wrapf(err, "invalid pattern: %q", pattern)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package walk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+//  Tests
+// ============================================================================
+
+func Test_matchGlob(t *testing.T) {
+	t.Parallel()
+
+	for _, test := range []struct {
+		pattern string
+		name    string
+		expect  bool
+	}{
+		{pattern: "*.go", name: "main.go", expect: true},
+		{pattern: "*.go", name: "cmd/main.go", expect: false},
+		{pattern: "cmd/*.go", name: "cmd/main.go", expect: true},
+		{pattern: "**/*.go", name: "main.go", expect: true},
+		{pattern: "**/*.go", name: "a/b/c/main.go", expect: true},
+		{pattern: "a/**/c", name: "a/c", expect: true},
+		{pattern: "a/**/c", name: "a/b/b/c", expect: true},
+		{pattern: "a/**/c", name: "a/b/b/d", expect: false},
+		{pattern: "a/**", name: "a/b/c", expect: true},
+		{pattern: "a/**", name: "b/c", expect: false},
+		{pattern: "a/b", name: "a", expect: false},
+		{pattern: "[abc].txt", name: "b.txt", expect: true},
+		{pattern: "[", name: "[", expect: false},
+	} {
+		require.Equal(t, test.expect, matchGlob(test.pattern, test.name), "pattern: %q, name: %q", test.pattern, test.name)
+	}
+}
+
+func Test_matchName(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, matchName("*.go", "a/b/main.go"), "pattern without slash should match the base name")
+	require.True(t, matchName("/a/*.go", "a/main.go"), "leading slash should be the root")
+	require.False(t, matchName("b/*.go", "a/b/main.go"), "pattern with slash should match the whole path")
+}
+
+func Test_validateGlob(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, validateGlob("**/*.go"))
+	require.ErrorContains(t, validateGlob("a/[/b"), `invalid pattern: "a/[/b"`)
+}
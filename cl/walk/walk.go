@@ -0,0 +1,251 @@
+/*
+Package walk walks the directory tree and counts the number of lines of the files
+concurrently.
+
+The files to count are filtered by the include and exclude glob patterns and the
+.gitignore files in the tree.
+*/
+package walk
+
+import (
+	"io/fs"
+	"path/filepath"
+	"runtime"
+	"sync"
+
+	"github.com/KEINOS/go-countline/cl"
+	"github.com/pkg/errors"
+)
+
+// nameGitDir is the directory of the git repository which is always skipped.
+const nameGitDir = ".git"
+
+// ----------------------------------------------------------------------------
+//  Type: Options
+// ----------------------------------------------------------------------------
+
+// Options are the options to walk the directory tree. The zero value walks all
+// the files except the ones ignored by the .gitignore files.
+//
+// The patterns are of path.Match and "**" matches zero or more directories. The
+// pattern without a slash matches the base name at any depth, such as "*.go", and
+// the one with a slash matches the slash-separated path relative to the root, such
+// as "cmd/**/*.go".
+type Options struct {
+	// Include are the patterns of the files to walk. If empty, all the files are
+	// walked.
+	Include []string
+	// Exclude are the patterns of the files and directories to skip. It takes
+	// precedence over Include.
+	Exclude []string
+	// NoGitignore disables the .gitignore files.
+	NoGitignore bool
+	// Workers is the maximum number of the files to count concurrently. Default is
+	// the number of CPUs.
+	Workers int
+}
+
+// validate returns an error if any of the patterns is malformed.
+func (o Options) validate() error {
+	for _, patterns := range [][]string{o.Include, o.Exclude} {
+		for _, pattern := range patterns {
+			if err := validateGlob(pattern); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// included reports whether the file is walked by the include patterns.
+func (o Options) included(relPath string) bool {
+	if len(o.Include) == 0 {
+		return true
+	}
+
+	return matchAny(o.Include, relPath)
+}
+
+// matchAny reports whether the path matches any of the patterns.
+func matchAny(patterns []string, relPath string) bool {
+	for _, pattern := range patterns {
+		if matchName(pattern, relPath) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ----------------------------------------------------------------------------
+//  Walk
+// ----------------------------------------------------------------------------
+
+// Walk walks the directory tree of the root and calls fn with the path of each
+// regular file that passes the filters, in lexical order. The path is joined with
+// the root the same as filepath.WalkDir.
+//
+// The ".git" directories and the symbolic links are skipped. If fn returns an
+// error, Walk stops and returns it.
+func Walk(root string, opts Options, fn func(pathFile string) error) error {
+	if err := opts.validate(); err != nil {
+		return err
+	}
+
+	ignores := ignoreTree{}
+
+	err := filepath.WalkDir(root, func(pathFile string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(root, pathFile)
+		if err != nil {
+			return err //nolint:wrapcheck // wrapped below
+		}
+
+		relPath = filepath.ToSlash(relPath)
+
+		if entry.IsDir() {
+			return visitDir(root, relPath, entry, opts, ignores)
+		}
+
+		if !entry.Type().IsRegular() || skipped(relPath, false, opts, ignores) || !opts.included(relPath) {
+			return nil
+		}
+
+		return fn(pathFile)
+	})
+
+	return errors.Wrap(err, "failed to walk directory")
+}
+
+// visitDir returns filepath.SkipDir if the directory is skipped. Otherwise it
+// loads the .gitignore file in it.
+func visitDir(root, relPath string, entry fs.DirEntry, opts Options, ignores ignoreTree) error {
+	if relPath != "." && (entry.Name() == nameGitDir || skipped(relPath, true, opts, ignores)) {
+		return filepath.SkipDir
+	}
+
+	if opts.NoGitignore {
+		return nil
+	}
+
+	return ignores.load(root, relPath)
+}
+
+// skipped reports whether the path is excluded or ignored.
+func skipped(relPath string, isDir bool, opts Options, ignores ignoreTree) bool {
+	return matchAny(opts.Exclude, relPath) || ignores.ignored(relPath, isDir)
+}
+
+// ----------------------------------------------------------------------------
+//  Type: File
+// ----------------------------------------------------------------------------
+
+// File is the number of lines of a file.
+type File struct {
+	// Err is the error to count the file. Lines is zero if it is not nil.
+	Err error
+	// Path is the path of the file joined with the root.
+	Path string
+	// Lines is the number of lines counted by cl.CountLinesFile64.
+	Lines uint64
+}
+
+// ----------------------------------------------------------------------------
+//  Type: Result
+// ----------------------------------------------------------------------------
+
+// Result is the number of lines of the files in the directory tree.
+type Result struct {
+	// Files are the counted files in lexical order, including the ones failed
+	// to count.
+	Files []File
+	// Total is the sum of the lines of the Files.
+	Total uint64
+}
+
+// Err returns the first error of the files if any.
+func (r *Result) Err() error {
+	for _, file := range r.Files {
+		if file.Err != nil {
+			return file.Err
+		}
+	}
+
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+//  Count
+// ----------------------------------------------------------------------------
+
+// Count walks the directory tree of the root and counts the lines of the files.
+// The files are counted concurrently with at most opts.Workers goroutines while
+// walking the tree.
+//
+// The error to count a file is stored in the File and does not stop the others.
+// It returns an error only if the walk itself fails.
+func Count(root string, opts Options) (*Result, error) {
+	numWorkers := opts.Workers
+	if numWorkers <= 0 {
+		numWorkers = runtime.GOMAXPROCS(0)
+	}
+
+	type job struct {
+		path  string
+		index int
+	}
+
+	jobs := make(chan job)
+	files := []File{}
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+
+	for range numWorkers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for job := range jobs {
+				lines, err := cl.CountLinesFile64(job.path)
+
+				mu.Lock()
+				files[job.index] = File{Path: job.path, Lines: lines, Err: err}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	err := Walk(root, opts, func(pathFile string) error {
+		mu.Lock()
+		files = append(files, File{Path: pathFile})
+		index := len(files) - 1
+		mu.Unlock()
+
+		jobs <- job{path: pathFile, index: index}
+
+		return nil
+	})
+
+	close(jobs)
+	wg.Wait()
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Files: files}
+
+	for _, file := range files {
+		result.Total += file.Lines
+	}
+
+	return result, nil
+}
@@ -0,0 +1,181 @@
+package walk
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// ============================================================================
+//  Tests
+// ============================================================================
+
+func TestWalk(t *testing.T) {
+	t.Parallel()
+
+	root := makeTree(t)
+
+	for _, test := range []struct {
+		name   string
+		opts   Options
+		expect []string
+	}{
+		{
+			name:   "default",
+			expect: []string{".gitignore", "README.md", "cmd/app/main.go", "docs/guide.md", "keep.log", "main.go", "sub/.gitignore", "sub/keep.log"},
+		},
+		{
+			name: "no gitignore",
+			opts: Options{NoGitignore: true},
+			expect: []string{
+				".gitignore", "README.md", "app.log", "build/out.txt", "cmd/app/main.go", "docs/guide.md",
+				"keep.log", "main.go", "sub/.gitignore", "sub/keep.log", "sub/local.txt",
+			},
+		},
+		{
+			name:   "include",
+			opts:   Options{Include: []string{"*.go"}},
+			expect: []string{"cmd/app/main.go", "main.go"},
+		},
+		{
+			name:   "include with path",
+			opts:   Options{Include: []string{"/*.go", "docs/**"}},
+			expect: []string{"docs/guide.md", "main.go"},
+		},
+		{
+			name:   "exclude",
+			opts:   Options{Include: []string{"*.go", "*.md"}, Exclude: []string{"cmd", "README.*"}},
+			expect: []string{"docs/guide.md", "main.go"},
+		},
+	} {
+		actual := []string{}
+
+		err := Walk(root, test.opts, func(pathFile string) error {
+			relPath, err := filepath.Rel(root, pathFile)
+			require.NoError(t, err)
+
+			actual = append(actual, filepath.ToSlash(relPath))
+
+			return nil
+		})
+
+		require.NoError(t, err, test.name)
+		require.Equal(t, test.expect, actual, test.name)
+	}
+}
+
+func TestWalk_errors(t *testing.T) {
+	t.Parallel()
+
+	root := makeTree(t)
+	noop := func(string) error { return nil }
+
+	err := Walk(root, Options{Exclude: []string{"["}}, noop)
+	require.ErrorContains(t, err, "invalid pattern")
+
+	err = Walk(filepath.Join(root, "missing"), Options{}, noop)
+	require.ErrorContains(t, err, "failed to walk directory")
+
+	errStop := errors.New("stop")
+
+	err = Walk(root, Options{}, func(string) error { return errStop })
+	require.ErrorIs(t, err, errStop, "error of the callback should stop the walk")
+
+	// .gitignore which can not be read
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "bad", nameGitignore), 0o700))
+
+	err = Walk(root, Options{}, noop)
+	require.ErrorContains(t, err, "failed to read .gitignore")
+}
+
+func TestCount(t *testing.T) {
+	t.Parallel()
+
+	root := makeTree(t)
+
+	for _, workers := range []int{0, 1, 3} {
+		result, err := Count(root, Options{Include: []string{"*.go", "*.log"}, Workers: workers})
+
+		require.NoError(t, err)
+		require.NoError(t, result.Err())
+		require.Equal(t, []File{
+			{Path: filepath.Join(root, "cmd", "app", "main.go"), Lines: 3},
+			{Path: filepath.Join(root, "keep.log"), Lines: 1},
+			{Path: filepath.Join(root, "main.go"), Lines: 1},
+			{Path: filepath.Join(root, "sub", "keep.log"), Lines: 2},
+		}, result.Files, "workers: %d", workers)
+		require.Equal(t, uint64(7), result.Total)
+	}
+}
+
+func TestCount_errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := Count(filepath.Join(t.TempDir(), "missing"), Options{})
+	require.ErrorContains(t, err, "failed to walk directory")
+
+	if os.Geteuid() == 0 {
+		t.Skip("root can read any file")
+	}
+
+	root := makeTree(t)
+	pathFile := filepath.Join(root, "main.go")
+
+	require.NoError(t, os.Chmod(pathFile, 0o000))
+
+	result, err := Count(root, Options{Include: []string{"*.go"}})
+
+	require.NoError(t, err, "error of a file should not stop counting")
+	require.ErrorContains(t, result.Err(), "failed to open file")
+	require.Equal(t, uint64(3), result.Total)
+}
+
+func TestResult_Err(t *testing.T) {
+	t.Parallel()
+
+	errFirst, errSecond := errors.New("first"), errors.New("second")
+
+	result := &Result{Files: []File{{Path: "a"}, {Path: "b", Err: errFirst}, {Path: "c", Err: errSecond}}}
+	require.ErrorIs(t, result.Err(), errFirst)
+
+	result = &Result{Files: []File{{Path: "a"}}}
+	require.NoError(t, result.Err())
+}
+
+// ============================================================================
+//  Helpers
+// ============================================================================
+
+// makeTree creates the directory tree for testing and returns the root.
+func makeTree(t *testing.T) string {
+	t.Helper()
+
+	root := t.TempDir()
+
+	for name, data := range map[string]string{
+		".gitignore":      "*.log\n!keep.log\nbuild/\n",
+		".git/HEAD":       "ref: refs/heads/main\n",
+		"README.md":       "# README\n",
+		"app.log":         "foo\n",
+		"build/out.txt":   "out\n",
+		"cmd/app/main.go": "package main\n\nfunc main() {}\n",
+		"docs/guide.md":   "# Guide\n",
+		"keep.log":        "keep\n",
+		"main.go":         "package main\n",
+		"sub/.gitignore":  "/local.txt\n",
+		"sub/keep.log":    "keep\nkeep\n",
+		"sub/local.txt":   "local\n",
+	} {
+		pathFile := filepath.Join(root, filepath.FromSlash(name))
+
+		require.NoError(t, os.MkdirAll(filepath.Dir(pathFile), 0o700))
+		require.NoError(t, os.WriteFile(pathFile, []byte(data), 0o600))
+	}
+
+	require.NoError(t, os.Symlink("main.go", filepath.Join(root, "link.go")))
+
+	return root
+}